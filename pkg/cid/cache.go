@@ -0,0 +1,49 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cid
+
+import (
+	"crypto/x509"
+	"sync"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/attrmgr"
+)
+
+// maxCachedIdentities bounds the number of parsed identities that are kept.
+// The cache is cleared when it is full.
+const maxCachedIdentities = 128
+
+// identityCache holds the identities parsed from creator bytes, so that
+// repeated identity checks in a transaction do not parse the creator's
+// certificate again.
+var identityCache = &parsedIdentityCache{}
+
+// parsedIdentity is the result of parsing the creator bytes. It is shared
+// between ClientID instances and must not be modified.
+type parsedIdentity struct {
+	mspID string
+	cert  *x509.Certificate
+	attrs *attrmgr.Attributes
+}
+
+type parsedIdentityCache struct {
+	mutex      sync.Mutex
+	identities map[string]*parsedIdentity
+}
+
+func (pc *parsedIdentityCache) get(creator []byte) (*parsedIdentity, bool) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	id, ok := pc.identities[string(creator)]
+	return id, ok
+}
+
+func (pc *parsedIdentityCache) put(creator []byte, id *parsedIdentity) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	if pc.identities == nil || len(pc.identities) >= maxCachedIdentities {
+		pc.identities = map[string]*parsedIdentity{}
+	}
+	pc.identities[string(creator)] = id
+}
@@ -0,0 +1,31 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cid
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsedIdentityCache(t *testing.T) {
+	cache := &parsedIdentityCache{}
+
+	_, ok := cache.get([]byte("creator"))
+	assert.False(t, ok)
+
+	id := &parsedIdentity{mspID: "SampleOrg"}
+	cache.put([]byte("creator"), id)
+	cached, ok := cache.get([]byte("creator"))
+	assert.True(t, ok)
+	assert.Same(t, id, cached)
+
+	for i := 0; i < maxCachedIdentities; i++ {
+		cache.put([]byte(fmt.Sprintf("creator%d", i)), &parsedIdentity{})
+	}
+	assert.LessOrEqual(t, len(cache.identities), maxCachedIdentities)
+	_, ok = cache.get([]byte("creator"))
+	assert.False(t, ok, "cache should be cleared when full")
+}
@@ -64,6 +64,16 @@ func HasOUValue(stub ChaincodeStubInterface, OUValue string) (bool, error) {
 	return c.HasOUValue(OUValue)
 }
 
+// IsIdemix returns true if the client was identified by an idemix credential
+// rather than an X509 certificate.
+func IsIdemix(stub ChaincodeStubInterface) (bool, error) {
+	c, err := New(stub)
+	if err != nil {
+		return false, err
+	}
+	return c.IsIdemix(), nil
+}
+
 // GetX509Certificate returns the X509 certificate associated with the client,
 // or nil if it was not identified by an X509 certificate.
 func GetX509Certificate(stub ChaincodeStubInterface) (*x509.Certificate, error) {
@@ -94,10 +104,10 @@ func New(stub ChaincodeStubInterface) (*ClientID, error) {
 
 // GetID returns a unique ID associated with the invoking identity.
 func (c *ClientID) GetID() (string, error) {
-	// When IdeMix, c.cert is nil for x509 type
-	// Here will return "", as there is no x509 type cert for generate id value with logic below.
+	// Idemix identities are anonymous, so there is no certificate from
+	// which a unique ID can be derived.
 	if c.cert == nil {
-		return "", fmt.Errorf("cannot determine identity")
+		return "", errNoX509Certificate("determine identity")
 	}
 	// The leading "x509::" distinguishes this as an X509 certificate, and
 	// the subject and issuer DNs uniquely identify the X509 certificate.
@@ -135,12 +145,16 @@ func (c *ClientID) AssertAttributeValue(attrName, attrValue string) error {
 	return nil
 }
 
-// HasOUValue checks if an OU with the specified value is present
+// HasOUValue checks if an OU with the specified value is present. For idemix
+// identities the OU of the credential is checked.
 func (c *ClientID) HasOUValue(OUValue string) (bool, error) {
 	x509Cert := c.cert
 	if x509Cert == nil {
-		// Here it will return false and an error, as there is no x509 type cert to check for OU values.
-		return false, fmt.Errorf("cannot obtain an X509 certificate for the identity")
+		ou, found, err := c.GetAttributeValue("ou")
+		if err != nil {
+			return false, err
+		}
+		return found && ou == OUValue, nil
 	}
 
 	for _, OU := range x509Cert.Subject.OrganizationalUnit {
@@ -152,25 +166,65 @@ func (c *ClientID) HasOUValue(OUValue string) (bool, error) {
 }
 
 // GetX509Certificate returns the X509 certificate associated with the client,
-// or nil if it was not identified by an X509 certificate.
+// or nil if it was not identified by an X509 certificate. The certificate is
+// a copy, as the parsed certificate is cached and shared between
+// transactions of the same client.
 func (c *ClientID) GetX509Certificate() (*x509.Certificate, error) {
-	return c.cert, nil
+	if c.cert == nil {
+		return nil, nil
+	}
+	cert, err := x509.ParseCertificate(c.cert.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy certificate: %s", err)
+	}
+	return cert, nil
+}
+
+// IsIdemix returns true if the client was identified by an idemix credential
+// rather than an X509 certificate. Idemix identities only expose the "ou" and
+// "role" attributes; GetID and other functions that need an X509 certificate
+// return an error for them.
+func (c *ClientID) IsIdemix() bool {
+	return c.cert == nil
+}
+
+func errNoX509Certificate(operation string) error {
+	return fmt.Errorf("cannot %s: the identity is an idemix credential and has no X509 certificate", operation)
 }
 
 // Initialize the client
 func (c *ClientID) init() error {
-	signingID, err := c.getIdentity()
-	if err != nil {
+	creator, err := c.stub.GetCreator()
+	if err != nil || creator == nil {
+		return fmt.Errorf("failed to get transaction invoker's identity from the chaincode stub: %w", err)
+	}
+	if id, ok := identityCache.get(creator); ok {
+		c.mspID, c.cert, c.attrs = id.mspID, id.cert, id.attrs
+		return nil
+	}
+	if err := c.parse(creator); err != nil {
 		return err
 	}
+	identityCache.put(creator, &parsedIdentity{mspID: c.mspID, cert: c.cert, attrs: c.attrs})
+	return nil
+}
+
+// Parse the bytes returned by ChaincodeStubInterface.GetCreator method
+func (c *ClientID) parse(creator []byte) error {
+	signingID := &msp.SerializedIdentity{}
+	err := proto.Unmarshal(creator, signingID)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal transaction invoker's identity: %w", err)
+	}
 	c.mspID = signingID.GetMspid()
 	idbytes := signingID.GetIdBytes()
 	block, _ := pem.Decode(idbytes)
 	if block == nil {
-		err := c.getAttributesFromIdemix()
+		attrs, err := attrmgr.New().GetAttributesFromIdemix(creator)
 		if err != nil {
-			return fmt.Errorf("identity bytes are neither X509 PEM format nor an idemix credential: %s", err)
+			return fmt.Errorf("identity bytes are neither X509 PEM format nor an idemix credential: failed to get attributes from the transaction invoker's idemix credential: %w", err)
 		}
+		c.attrs = attrs
 		return nil
 	}
 	cert, err := x509.ParseCertificate(block.Bytes)
@@ -186,34 +240,6 @@ func (c *ClientID) init() error {
 	return nil
 }
 
-// Unmarshals the bytes returned by ChaincodeStubInterface.GetCreator method and
-// returns the resulting msp.SerializedIdentity object
-func (c *ClientID) getIdentity() (*msp.SerializedIdentity, error) {
-	sid := &msp.SerializedIdentity{}
-	creator, err := c.stub.GetCreator()
-	if err != nil || creator == nil {
-		return nil, fmt.Errorf("failed to get transaction invoker's identity from the chaincode stub: %w", err)
-	}
-	err = proto.Unmarshal(creator, sid)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal transaction invoker's identity: %w", err)
-	}
-	return sid, nil
-}
-
-func (c *ClientID) getAttributesFromIdemix() error {
-	creator, err := c.stub.GetCreator()
-	if err != nil || creator == nil {
-		return fmt.Errorf("failed to get transaction invoker's identity from the chaincode stub: %w", err)
-	}
-	attrs, err := attrmgr.New().GetAttributesFromIdemix(creator)
-	if err != nil {
-		return fmt.Errorf("failed to get attributes from the transaction invoker's idemix credential: %w", err)
-	}
-	c.attrs = attrs
-	return nil
-}
-
 // Get the DN (distinguished name) associated with a pkix.Name.
 // NOTE: This code is almost a direct copy of the String() function in
 // https://go-review.googlesource.com/c/go/+/67270/1/src/crypto/x509/pkix/pkix.go#26
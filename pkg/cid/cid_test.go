@@ -73,6 +73,9 @@ func TestClient(t *testing.T) {
 	found, err = cid.HasOUValue(stub, "foo")
 	assert.NoError(t, err, "HasOUValue")
 	assert.False(t, found, "OU 'foo' should not be found in the submitter cert")
+	idemix, err := cid.IsIdemix(stub)
+	assert.NoError(t, err, "IsIdemix")
+	assert.False(t, idemix, "X509 identity should not be recognized as idemix")
 
 	stub, err = getMockStubWithAttrs()
 	assert.NoError(t, err, "Failed to get mock submitter")
@@ -129,6 +132,30 @@ func TestIdemix(t *testing.T) {
 	_, found, err = sinfo.GetAttributeValue("id")
 	assert.NoError(t, err, "GetAttributeValue")
 	assert.False(t, found, "Attribute 'id' should not be found in the submitter cert")
+	assert.True(t, sinfo.IsIdemix(), "Identity should be recognized as idemix")
+	_, err = cid.GetID(stub)
+	assert.EqualError(t, err, "cannot determine identity: the identity is an idemix credential and has no X509 certificate")
+	found, err = sinfo.HasOUValue("org1.department1")
+	assert.NoError(t, err, "HasOUValue")
+	assert.True(t, found, "OU of the idemix credential should be found")
+	found, err = sinfo.HasOUValue("org1.department2")
+	assert.NoError(t, err, "HasOUValue")
+	assert.False(t, found, "Other OU should not be found")
+}
+
+func TestGetX509CertificateReturnsCopy(t *testing.T) {
+	stub, err := getMockStub()
+	assert.NoError(t, err, "Failed to get mock submitter")
+	cert, err := cid.GetX509Certificate(stub)
+	assert.NoError(t, err, "GetX509Certificate")
+	cert.Subject.OrganizationalUnit = []string{"foo"}
+
+	found, err := cid.HasOUValue(stub, "foo")
+	assert.NoError(t, err, "HasOUValue")
+	assert.False(t, found, "modifying the returned certificate should not affect later calls")
+	cert, err = cid.GetX509Certificate(stub)
+	assert.NoError(t, err, "GetX509Certificate")
+	assert.Equal(t, []string{"Fabric"}, cert.Subject.OrganizationalUnit)
 }
 
 func getMockStub() (cid.ChaincodeStubInterface, error) {
@@ -14,6 +14,8 @@ type ChaincodeStubInterface interface {
 	GetCreator() ([]byte, error)
 }
 
+//go:generate counterfeiter -o mocks/client_identity.go --fake-name ClientIdentity . ClientIdentity
+
 // ClientIdentity represents information about the identity that submitted the
 // transaction
 type ClientIdentity interface {
@@ -36,7 +38,7 @@ type ClientIdentity interface {
 	// with a value of `attrValue`; otherwise, an error is returned.
 	AssertAttributeValue(attrName, attrValue string) error
 
-	// GetX509Certificate returns the X509 certificate associated with the client,
-	// or nil if it was not identified by an X509 certificate.
+	// GetX509Certificate returns a copy of the X509 certificate associated with
+	// the client, or nil if it was not identified by an X509 certificate.
 	GetX509Certificate() (*x509.Certificate, error)
 }
@@ -0,0 +1,76 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cid
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"fmt"
+)
+
+// VerifySignature checks that signature is a valid signature of payload made
+// with the private key of the identity that submitted the transaction.
+func VerifySignature(stub ChaincodeStubInterface, payload, signature []byte) error {
+	c, err := New(stub)
+	if err != nil {
+		return err
+	}
+	return c.VerifySignature(payload, signature)
+}
+
+// VerifySignature checks that signature is a valid signature of payload made
+// with the private key of the client's X509 certificate.
+func (c *ClientID) VerifySignature(payload, signature []byte) error {
+	if c.cert == nil {
+		return errNoX509Certificate("verify signature")
+	}
+	return VerifySignatureWithCertificate(c.cert, payload, signature)
+}
+
+// VerifySignatureWithCertificate checks that signature is a valid signature of
+// payload made with the private key of cert. ECDSA signatures must be ASN.1
+// encoded and made over the SHA-256, SHA-384 or SHA-512 digest of payload for
+// the P-256, P-384 and P-521 curves respectively. Ed25519 signatures are made
+// over payload itself.
+func VerifySignatureWithCertificate(cert *x509.Certificate, payload, signature []byte) error {
+	if cert == nil {
+		return fmt.Errorf("certificate must not be nil")
+	}
+
+	switch pub := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		hash, err := curveHash(pub.Curve)
+		if err != nil {
+			return err
+		}
+		h := hash.New()
+		h.Write(payload)
+		if !ecdsa.VerifyASN1(pub, h.Sum(nil), signature) {
+			return fmt.Errorf("invalid ECDSA signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, payload, signature) {
+			return fmt.Errorf("invalid Ed25519 signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", cert.PublicKey)
+	}
+	return nil
+}
+
+func curveHash(curve elliptic.Curve) (crypto.Hash, error) {
+	switch curve {
+	case elliptic.P256():
+		return crypto.SHA256, nil
+	case elliptic.P384():
+		return crypto.SHA384, nil
+	case elliptic.P521():
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported elliptic curve %s", curve.Params().Name)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cid_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte("approve transfer of asset1")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := newTestCertificate(t, key.Public(), key)
+	digest := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	sid := &msp.SerializedIdentity{
+		Mspid:   "SampleOrg",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+	}
+	creator, err := proto.Marshal(sid)
+	require.NoError(t, err)
+	var stub cid.ChaincodeStubInterface = &mockStub{creator: creator}
+
+	assert.NoError(t, cid.VerifySignature(stub, payload, signature))
+	assert.EqualError(t, cid.VerifySignature(stub, []byte("tampered"), signature), "invalid ECDSA signature")
+
+	stub, err = getMockStub()
+	require.NoError(t, err)
+	assert.EqualError(t, cid.VerifySignature(stub, payload, signature), "invalid ECDSA signature", "signature should not verify against another identity")
+
+	stub, err = getIdemixMockStubWithAttrs()
+	require.NoError(t, err)
+	assert.EqualError(t, cid.VerifySignature(stub, payload, signature), "cannot verify signature: the identity is an idemix credential and has no X509 certificate")
+}
+
+func TestVerifySignatureWithCertificate(t *testing.T) {
+	payload := []byte("approve transfer of asset1")
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	ecCert := newTestCertificate(t, ecKey.Public(), ecKey)
+	h := crypto.SHA384.New()
+	h.Write(payload)
+	ecSignature, err := ecdsa.SignASN1(rand.Reader, ecKey, h.Sum(nil))
+	require.NoError(t, err)
+
+	assert.NoError(t, cid.VerifySignatureWithCertificate(ecCert, payload, ecSignature))
+	assert.EqualError(t, cid.VerifySignatureWithCertificate(ecCert, payload, []byte("bad")), "invalid ECDSA signature")
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edCert := newTestCertificate(t, edPub, edKey)
+	edSignature := ed25519.Sign(edKey, payload)
+
+	assert.NoError(t, cid.VerifySignatureWithCertificate(edCert, payload, edSignature))
+	assert.EqualError(t, cid.VerifySignatureWithCertificate(edCert, []byte("tampered"), edSignature), "invalid Ed25519 signature")
+
+	assert.EqualError(t, cid.VerifySignatureWithCertificate(nil, payload, edSignature), "certificate must not be nil")
+}
+
+func newTestCertificate(t *testing.T, pub crypto.PublicKey, priv crypto.Signer) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
@@ -0,0 +1,76 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package crosschannel provides helpers to query chaincode deployed on other
+// channels.
+//
+// A chaincode invoked on another channel runs as a query: its read set and
+// write set are not applied to the calling transaction, so any state it
+// writes is discarded and its reads are not validated at commit. The helpers
+// only allow calls to other channels, so that this read-only behaviour is
+// always what the caller gets.
+package crosschannel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+// RemoteError is returned when the chaincode on the other channel returns an
+// error response, as opposed to errors raised by the calling chaincode.
+type RemoteError struct {
+	Channel   string
+	Chaincode string
+	Status    int32
+	Message   string
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("chaincode %s on channel %s returned status %d: %s", e.Chaincode, e.Channel, e.Status, e.Message)
+}
+
+// Query invokes chaincodeName on channel with the given arguments and returns
+// the response payload. channel must not be the channel of the current
+// transaction. If the invoked chaincode returns an error response, the error
+// is a *RemoteError.
+func Query(stub ChaincodeStubInterface, channel, chaincodeName string, args ...[]byte) ([]byte, error) {
+	if channel == "" {
+		return nil, errors.New("channel must be specified")
+	}
+	if channel == stub.GetChannelID() {
+		return nil, fmt.Errorf("chaincode %s is on the current channel %s; use InvokeChaincode to call it with read-write semantics", chaincodeName, channel)
+	}
+	if chaincodeName == "" {
+		return nil, errors.New("chaincode name must be specified")
+	}
+
+	response := stub.InvokeChaincode(chaincodeName, args, channel)
+	if response == nil {
+		return nil, fmt.Errorf("no response from chaincode %s on channel %s", chaincodeName, channel)
+	}
+	if response.GetStatus() >= shim.ERRORTHRESHOLD {
+		return nil, &RemoteError{
+			Channel:   channel,
+			Chaincode: chaincodeName,
+			Status:    response.GetStatus(),
+			Message:   response.GetMessage(),
+		}
+	}
+	return response.GetPayload(), nil
+}
+
+// QueryJSON invokes chaincodeName on channel like Query and decodes the JSON
+// response payload into result.
+func QueryJSON(stub ChaincodeStubInterface, channel, chaincodeName string, result interface{}, args ...[]byte) error {
+	payload, err := Query(stub, channel, chaincodeName, args...)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return fmt.Errorf("failed to decode response from chaincode %s on channel %s: %s", chaincodeName, channel, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crosschannel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/crosschannel"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim/mocks"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStub(response *peer.Response) *mocks.ChaincodeStub {
+	stub := &mocks.ChaincodeStub{}
+	stub.GetChannelIDReturns("mychannel")
+	stub.InvokeChaincodeReturns(response)
+	return stub
+}
+
+func TestQuery(t *testing.T) {
+	stub := newStub(shim.Success([]byte("payload")))
+
+	payload, err := crosschannel.Query(stub, "otherchannel", "othercc", []byte("get"), []byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("payload"), payload)
+	assert.Equal(t, 1, stub.InvokeChaincodeCallCount())
+	chaincodeName, args, channel := stub.InvokeChaincodeArgsForCall(0)
+	assert.Equal(t, "othercc", chaincodeName)
+	assert.Equal(t, [][]byte{[]byte("get"), []byte("key")}, args)
+	assert.Equal(t, "otherchannel", channel)
+
+	_, err = crosschannel.Query(stub, "mychannel", "othercc")
+	assert.EqualError(t, err, "chaincode othercc is on the current channel mychannel; use InvokeChaincode to call it with read-write semantics")
+	_, err = crosschannel.Query(stub, "", "othercc")
+	assert.EqualError(t, err, "channel must be specified")
+	_, err = crosschannel.Query(stub, "otherchannel", "")
+	assert.EqualError(t, err, "chaincode name must be specified")
+	assert.Equal(t, 1, stub.InvokeChaincodeCallCount(), "invalid queries should not invoke a chaincode")
+
+	stub.InvokeChaincodeReturns(nil)
+	_, err = crosschannel.Query(stub, "otherchannel", "othercc")
+	assert.EqualError(t, err, "no response from chaincode othercc on channel otherchannel")
+}
+
+func TestQueryRemoteError(t *testing.T) {
+	stub := newStub(shim.Error("asset not found"))
+
+	_, err := crosschannel.Query(stub, "otherchannel", "othercc")
+	assert.EqualError(t, err, "chaincode othercc on channel otherchannel returned status 500: asset not found")
+
+	var remoteErr *crosschannel.RemoteError
+	assert.True(t, errors.As(err, &remoteErr))
+	assert.Equal(t, &crosschannel.RemoteError{Channel: "otherchannel", Chaincode: "othercc", Status: shim.ERROR, Message: "asset not found"}, remoteErr)
+}
+
+func TestQueryJSON(t *testing.T) {
+	stub := newStub(shim.Success([]byte(`{"id":"asset1","value":5}`)))
+
+	var asset struct {
+		ID    string `json:"id"`
+		Value int    `json:"value"`
+	}
+	assert.NoError(t, crosschannel.QueryJSON(stub, "otherchannel", "othercc", &asset, []byte("get")))
+	assert.Equal(t, "asset1", asset.ID)
+	assert.Equal(t, 5, asset.Value)
+
+	stub.InvokeChaincodeReturns(shim.Success([]byte("not json")))
+	err := crosschannel.QueryJSON(stub, "otherchannel", "othercc", &asset)
+	assert.ErrorContains(t, err, "failed to decode response from chaincode othercc on channel otherchannel")
+
+	stub.InvokeChaincodeReturns(shim.Error("boom"))
+	err = crosschannel.QueryJSON(stub, "otherchannel", "othercc", &asset)
+	assert.IsType(t, &crosschannel.RemoteError{}, err)
+}
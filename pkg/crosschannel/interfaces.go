@@ -0,0 +1,17 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crosschannel
+
+import "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+
+// ChaincodeStubInterface is the subset of the chaincode stub used to query
+// chaincode on other channels.
+type ChaincodeStubInterface interface {
+	// GetChannelID returns the channel the proposal is sent to for chaincode to process.
+	GetChannelID() string
+
+	// InvokeChaincode locally calls the specified chaincode `Invoke` using the
+	// same transaction context.
+	InvokeChaincode(chaincodeName string, args [][]byte, channel string) *peer.Response
+}
@@ -0,0 +1,115 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package encryption provides helpers to store values in the world state
+// encrypted with AES-GCM.
+//
+// The encryption key is not stored on the ledger. It is usually supplied by
+// the client in the transient map of the proposal, which is not part of the
+// transaction, and retrieved with KeyFromTransient.
+//
+// Every endorsing peer must produce the same write set, so the nonce is
+// derived from the key, the transaction ID, the state key and the value
+// instead of being chosen at random.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// KeyFromTransient returns the AES key stored under name in the transient
+// map. The key must be 16, 24 or 32 bytes long.
+func KeyFromTransient(stub ChaincodeStubInterface, name string) ([]byte, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transient map: %s", err)
+	}
+	key, ok := transient[name]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %s not found in the transient map", name)
+	}
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PutState encrypts value with encKey and puts the result into the
+// transaction's writeset under key. The ciphertext is bound to key and cannot
+// be decrypted if copied to another key.
+func PutState(stub ChaincodeStubInterface, key string, value []byte, encKey []byte) error {
+	aead, err := newAEAD(encKey)
+	if err != nil {
+		return err
+	}
+
+	nonce := deriveNonce(encKey, stub.GetTxID(), key, value, aead.NonceSize())
+	ciphertext := aead.Seal(nonce, nonce, value, []byte(key))
+	return stub.PutState(key, ciphertext)
+}
+
+// GetState returns the value of key, decrypted with encKey. If the key does
+// not exist in the state database, (nil, nil) is returned.
+func GetState(stub ChaincodeStubInterface, key string, encKey []byte) ([]byte, error) {
+	aead, err := newAEAD(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if ciphertext == nil {
+		return nil, nil
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("value of key %s is not encrypted", key)
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	value, err := aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value of key %s: %s", key, err)
+	}
+	return value, nil
+}
+
+func checkKey(key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return errors.New("encryption key must be 16, 24 or 32 bytes long")
+	}
+}
+
+func newAEAD(encKey []byte) (cipher.AEAD, error) {
+	if err := checkKey(encKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveNonce returns a nonce that is the same on every endorser but differs
+// for every transaction, key and value.
+func deriveNonce(encKey []byte, txID string, key string, value []byte, size int) []byte {
+	mac := hmac.New(sha256.New, encKey)
+	for _, field := range [][]byte{[]byte(txID), []byte(key), value} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+		mac.Write(length[:])
+		mac.Write(field)
+	}
+	return mac.Sum(nil)[:size]
+}
@@ -0,0 +1,92 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package encryption_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/encryption"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyFromTransient(t *testing.T) {
+	encKey := bytes.Repeat([]byte{1}, 32)
+	stub := &mocks.ChaincodeStub{}
+	stub.GetTransientReturns(map[string][]byte{"ENCKEY": encKey, "SHORT": []byte("short")}, nil)
+
+	key, err := encryption.KeyFromTransient(stub, "ENCKEY")
+	assert.NoError(t, err)
+	assert.Equal(t, encKey, key)
+
+	_, err = encryption.KeyFromTransient(stub, "MISSING")
+	assert.EqualError(t, err, "encryption key MISSING not found in the transient map")
+
+	_, err = encryption.KeyFromTransient(stub, "SHORT")
+	assert.EqualError(t, err, "encryption key must be 16, 24 or 32 bytes long")
+
+	stub.GetTransientReturns(nil, errors.New("boom"))
+	_, err = encryption.KeyFromTransient(stub, "ENCKEY")
+	assert.EqualError(t, err, "failed to get transient map: boom")
+}
+
+func TestPutGetState(t *testing.T) {
+	encKey := bytes.Repeat([]byte{1}, 16)
+	state := map[string][]byte{}
+	stub := newStub("tx1", state)
+
+	require.NoError(t, encryption.PutState(stub, "asset1", []byte("secret"), encKey))
+	assert.NotContains(t, string(state["asset1"]), "secret")
+
+	value, err := encryption.GetState(stub, "asset1", encKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), value)
+
+	value, err = encryption.GetState(stub, "missing", encKey)
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	_, err = encryption.GetState(stub, "asset1", bytes.Repeat([]byte{2}, 16))
+	assert.ErrorContains(t, err, "failed to decrypt value of key asset1")
+
+	state["asset2"] = state["asset1"]
+	_, err = encryption.GetState(stub, "asset2", encKey)
+	assert.ErrorContains(t, err, "failed to decrypt value of key asset2", "ciphertext should be bound to its key")
+
+	state["asset3"] = []byte("plain")
+	_, err = encryption.GetState(stub, "asset3", encKey)
+	assert.EqualError(t, err, "value of key asset3 is not encrypted")
+
+	assert.EqualError(t, encryption.PutState(stub, "asset1", []byte("secret"), []byte("bad")), "encryption key must be 16, 24 or 32 bytes long")
+}
+
+func TestPutStateDeterministic(t *testing.T) {
+	encKey := bytes.Repeat([]byte{1}, 24)
+	peer1, peer2, other := map[string][]byte{}, map[string][]byte{}, map[string][]byte{}
+
+	require.NoError(t, encryption.PutState(newStub("tx1", peer1), "asset1", []byte("secret"), encKey))
+	require.NoError(t, encryption.PutState(newStub("tx1", peer2), "asset1", []byte("secret"), encKey))
+	require.NoError(t, encryption.PutState(newStub("tx2", other), "asset1", []byte("secret"), encKey))
+
+	assert.Equal(t, peer1["asset1"], peer2["asset1"], "endorsers should produce the same ciphertext")
+	assert.NotEqual(t, peer1["asset1"], other["asset1"], "transactions should not share a nonce")
+}
+
+// newStub returns a ChaincodeStub fake for the transaction txID, backed by
+// state.
+func newStub(txID string, state map[string][]byte) *mocks.ChaincodeStub {
+	stub := &mocks.ChaincodeStub{}
+	stub.GetTxIDReturns(txID)
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		return state[key], nil
+	}
+	stub.PutStateStub = func(key string, value []byte) error {
+		state[key] = value
+		return nil
+	}
+	return stub
+}
@@ -0,0 +1,23 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package encryption
+
+// ChaincodeStubInterface is the subset of the chaincode stub used to read and
+// write encrypted state.
+type ChaincodeStubInterface interface {
+	// GetTxID returns the tx_id of the transaction proposal, which is unique per
+	// transaction and per client.
+	GetTxID() string
+
+	// GetTransient returns the `ChaincodeProposalPayload.Transient` field.
+	GetTransient() (map[string][]byte, error)
+
+	// GetState returns the value of the specified `key` from the
+	// ledger.
+	GetState(key string) ([]byte, error)
+
+	// PutState puts the specified `key` and `value` into the transaction's
+	// writeset as a data-write proposal.
+	PutState(key string, value []byte) error
+}
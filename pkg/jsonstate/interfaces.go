@@ -0,0 +1,12 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonstate
+
+// ChaincodeStubInterface is the subset of the chaincode stub used to write
+// JSON encoded state.
+type ChaincodeStubInterface interface {
+	// PutState puts the specified `key` and `value` into the transaction's
+	// writeset as a data-write proposal.
+	PutState(key string, value []byte) error
+}
@@ -0,0 +1,69 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jsonstate provides helpers to write and read JSON encoded values
+// in the world state without repeating the encoding boilerplate for every
+// key or query result.
+package jsonstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+// PutStates encodes each value of values as JSON and writes it under its key.
+// All values are encoded before any is written, so a value that cannot be
+// encoded leaves the state untouched. Keys are processed in sorted order, so
+// every endorser reports the same key when a value cannot be encoded or
+// written.
+func PutStates(stub ChaincodeStubInterface, values map[string]interface{}) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	encoded := make([][]byte, len(keys))
+	for i, key := range keys {
+		value, err := json.Marshal(values[key])
+		if err != nil {
+			return fmt.Errorf("failed to encode value of key %s: %s", key, err)
+		}
+		encoded[i] = value
+	}
+
+	for i, key := range keys {
+		if err := stub.PutState(key, encoded[i]); err != nil {
+			return fmt.Errorf("failed to put value of key %s: %s", key, err)
+		}
+	}
+	return nil
+}
+
+// Collect drains iter, decoding the JSON value of each result into a T, and
+// closes it. If maxResults is positive and the query returns more results,
+// an error is returned instead, so that an unexpectedly large query does not
+// exhaust the chaincode's memory.
+func Collect[T any](iter shim.StateQueryIteratorInterface, maxResults int) ([]T, error) {
+	defer iter.Close() //nolint:errcheck
+
+	var results []T
+	for iter.HasNext() {
+		if maxResults > 0 && len(results) == maxResults {
+			return nil, fmt.Errorf("query returned more than %d results", maxResults)
+		}
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get next query result: %s", err)
+		}
+		var result T
+		if err := json.Unmarshal(kv.GetValue(), &result); err != nil {
+			return nil, fmt.Errorf("failed to decode value of key %s: %s", kv.GetKey(), err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,99 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonstate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/jsonstate"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim/mocks"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+)
+
+type asset struct {
+	ID    string `json:"id"`
+	Value int    `json:"value"`
+}
+
+func TestPutStates(t *testing.T) {
+	stub := &mocks.ChaincodeStub{}
+	err := jsonstate.PutStates(stub, map[string]interface{}{
+		"asset2": asset{ID: "asset2", Value: 2},
+		"asset1": asset{ID: "asset1", Value: 1},
+		"count":  2,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, stub.PutStateCallCount())
+	var keys []string
+	for i := 0; i < stub.PutStateCallCount(); i++ {
+		key, _ := stub.PutStateArgsForCall(i)
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"asset1", "asset2", "count"}, keys, "keys should be written in sorted order")
+	key, value := stub.PutStateArgsForCall(0)
+	assert.Equal(t, "asset1", key)
+	assert.JSONEq(t, `{"id":"asset1","value":1}`, string(value))
+}
+
+func TestPutStatesErrors(t *testing.T) {
+	stub := &mocks.ChaincodeStub{}
+	err := jsonstate.PutStates(stub, map[string]interface{}{
+		"asset1": asset{ID: "asset1"},
+		"bad":    make(chan int),
+	})
+	assert.ErrorContains(t, err, "failed to encode value of key bad")
+	assert.Equal(t, 0, stub.PutStateCallCount(), "nothing should be written when a value cannot be encoded")
+
+	stub.PutStateReturns(errors.New("boom"))
+	err = jsonstate.PutStates(stub, map[string]interface{}{"asset2": 2, "asset1": 1})
+	assert.EqualError(t, err, "failed to put value of key asset1: boom")
+}
+
+// newIterator returns a StateQueryIterator fake that returns kvs.
+func newIterator(kvs ...*queryresult.KV) *mocks.StateQueryIterator {
+	iter := &mocks.StateQueryIterator{}
+	for i, kv := range kvs {
+		iter.HasNextReturnsOnCall(i, true)
+		iter.NextReturnsOnCall(i, kv, nil)
+	}
+	return iter
+}
+
+func TestCollect(t *testing.T) {
+	iter := newIterator(
+		&queryresult.KV{Key: "asset1", Value: []byte(`{"id":"asset1","value":1}`)},
+		&queryresult.KV{Key: "asset2", Value: []byte(`{"id":"asset2","value":2}`)},
+	)
+	assets, err := jsonstate.Collect[asset](iter, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []asset{{ID: "asset1", Value: 1}, {ID: "asset2", Value: 2}}, assets)
+	assert.Equal(t, 1, iter.CloseCallCount())
+
+	assets, err = jsonstate.Collect[asset](newIterator(), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, assets)
+}
+
+func TestCollectErrors(t *testing.T) {
+	kv := &queryresult.KV{Key: "asset1", Value: []byte(`{"id":"asset1"}`)}
+	iter := newIterator(kv, kv, kv)
+	_, err := jsonstate.Collect[asset](iter, 2)
+	assert.EqualError(t, err, "query returned more than 2 results")
+	assert.Equal(t, 1, iter.CloseCallCount())
+
+	_, err = jsonstate.Collect[asset](newIterator(kv, kv), 2)
+	assert.NoError(t, err, "a query returning exactly maxResults results should succeed")
+
+	_, err = jsonstate.Collect[asset](newIterator(&queryresult.KV{Key: "bad", Value: []byte("not json")}), 0)
+	assert.ErrorContains(t, err, "failed to decode value of key bad")
+
+	iter = &mocks.StateQueryIterator{}
+	iter.HasNextReturns(true)
+	iter.NextReturns(nil, errors.New("boom"))
+	_, err = jsonstate.Collect[asset](iter, 0)
+	assert.EqualError(t, err, "failed to get next query result: boom")
+}
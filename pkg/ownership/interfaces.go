@@ -0,0 +1,28 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ownership
+
+// ChaincodeStubInterface is the subset of the chaincode stub used to record
+// and enforce the ownership of ledger keys.
+type ChaincodeStubInterface interface {
+	// GetCreator returns `SignatureHeader.Creator` (e.g. an identity)
+	// of the `SignedProposal`. This is the identity of the agent (or user)
+	// submitting the transaction.
+	GetCreator() ([]byte, error)
+
+	// GetState returns the value of the specified `key` from the ledger.
+	GetState(key string) ([]byte, error)
+
+	// PutState puts the specified `key` and `value` into the transaction's
+	// writeset as a data-write proposal.
+	PutState(key string, value []byte) error
+
+	// DelState records the specified `key` to be deleted in the writeset of
+	// the transaction proposal.
+	DelState(key string) error
+
+	// CreateCompositeKey combines the given `attributes` to form a composite
+	// key.
+	CreateCompositeKey(objectType string, attributes []string) (string, error)
+}
@@ -0,0 +1,180 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ownership records the client identity that owns a ledger key and
+// enforces that only the owner may update it or hand it over to another
+// identity.
+//
+// Ownership records are stored in the world state under composite keys of
+// an object type reserved for this package, so they are part of the
+// transaction's read and write sets like any other state without clashing
+// with the chaincode's own composite keys.
+//
+// Owners are identified by their X509 certificate, so clients using idemix
+// credentials cannot own keys.
+package ownership
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+)
+
+// ownerObjectType is the object type of the composite keys ownership records
+// are stored under.
+const ownerObjectType = "github.com/hyperledger/fabric-chaincode-go/v2/pkg/ownership"
+
+// Owner identifies the client identity that owns a key.
+type Owner struct {
+	// MSPID is the ID of the MSP the owning identity belongs to
+	MSPID string `json:"mspid"`
+	// ID is the unique ID of the owning identity within its MSP, as
+	// returned by cid.GetID
+	ID string `json:"id"`
+}
+
+// NotOwnerError is returned when the identity submitting the transaction
+// does not own the key it attempts to modify.
+type NotOwnerError struct {
+	Key    string
+	Owner  Owner
+	Caller Owner
+}
+
+func (e *NotOwnerError) Error() string {
+	return fmt.Sprintf("identity %s of MSP %s is not the owner of key %s", e.Caller.ID, e.Caller.MSPID, e.Key)
+}
+
+// GetInvoker returns the Owner representation of the identity that
+// submitted the transaction. It fails for idemix identities, which have no
+// X509 certificate to derive an ID from.
+func GetInvoker(stub ChaincodeStubInterface) (Owner, error) {
+	c, err := cid.New(stub)
+	if err != nil {
+		return Owner{}, err
+	}
+	mspID, err := c.GetMSPID()
+	if err != nil {
+		return Owner{}, err
+	}
+	id, err := c.GetID()
+	if err != nil {
+		return Owner{}, fmt.Errorf("failed to get ID of the transaction invoker: %s", err)
+	}
+	return Owner{MSPID: mspID, ID: id}, nil
+}
+
+// GetOwner returns the owner recorded for key, or nil if the key has no
+// owner.
+func GetOwner(stub ChaincodeStubInterface, key string) (*Owner, error) {
+	ownerKey, err := createOwnerKey(stub, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := stub.GetState(ownerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read owner of key %s: %s", key, err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+	owner := &Owner{}
+	if err := json.Unmarshal(data, owner); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal owner of key %s: %s", key, err)
+	}
+	return owner, nil
+}
+
+// SetOwner records the identity that submitted the transaction as the owner
+// of key. If key already has an owner, only that owner may call SetOwner.
+// Idemix identities cannot own keys and always get an error.
+func SetOwner(stub ChaincodeStubInterface, key string) error {
+	invoker, err := GetInvoker(stub)
+	if err != nil {
+		return err
+	}
+	owner, err := GetOwner(stub, key)
+	if err != nil {
+		return err
+	}
+	if owner != nil && *owner != invoker {
+		return &NotOwnerError{Key: key, Owner: *owner, Caller: invoker}
+	}
+	return putOwner(stub, key, invoker)
+}
+
+// RequireOwner returns an error unless the identity that submitted the
+// transaction is the recorded owner of key. A key without an owner can not
+// be modified through RequireOwner and also results in an error, as does an
+// idemix identity, which cannot own keys.
+func RequireOwner(stub ChaincodeStubInterface, key string) error {
+	_, err := requireOwner(stub, key)
+	return err
+}
+
+// TransferOwnership hands key over to newOwner. Only the current owner of
+// key may transfer it.
+func TransferOwnership(stub ChaincodeStubInterface, key string, newOwner Owner) error {
+	if newOwner.MSPID == "" || newOwner.ID == "" {
+		return fmt.Errorf("new owner of key %s must have an MSP ID and an ID", key)
+	}
+	if _, err := requireOwner(stub, key); err != nil {
+		return err
+	}
+	return putOwner(stub, key, newOwner)
+}
+
+// DeleteOwner removes the ownership record of key. Only the current owner
+// of key may remove it.
+func DeleteOwner(stub ChaincodeStubInterface, key string) error {
+	if _, err := requireOwner(stub, key); err != nil {
+		return err
+	}
+	ownerKey, err := createOwnerKey(stub, key)
+	if err != nil {
+		return err
+	}
+	return stub.DelState(ownerKey)
+}
+
+func requireOwner(stub ChaincodeStubInterface, key string) (Owner, error) {
+	invoker, err := GetInvoker(stub)
+	if err != nil {
+		return Owner{}, err
+	}
+	owner, err := GetOwner(stub, key)
+	if err != nil {
+		return Owner{}, err
+	}
+	if owner == nil {
+		return Owner{}, fmt.Errorf("key %s has no owner", key)
+	}
+	if *owner != invoker {
+		return Owner{}, &NotOwnerError{Key: key, Owner: *owner, Caller: invoker}
+	}
+	return invoker, nil
+}
+
+func putOwner(stub ChaincodeStubInterface, key string, owner Owner) error {
+	ownerKey, err := createOwnerKey(stub, key)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(owner)
+	if err != nil {
+		return fmt.Errorf("failed to marshal owner of key %s: %s", key, err)
+	}
+	return stub.PutState(ownerKey, data)
+}
+
+// createOwnerKey returns the key under which the owner of key is stored. The
+// key is hex encoded so that composite keys, which contain U+0000, can be
+// owned as well.
+func createOwnerKey(stub ChaincodeStubInterface, key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("key must not be an empty string")
+	}
+	return stub.CreateCompositeKey(ownerObjectType, []string{hex.EncodeToString([]byte(key))})
+}
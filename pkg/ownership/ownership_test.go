@@ -0,0 +1,107 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ownership_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/internal/testcerts"
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/ownership"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnership(t *testing.T) {
+	stub := newStub(map[string][]byte{})
+	key, err := shim.CreateCompositeKey("asset", []string{"asset1"})
+	require.NoError(t, err)
+
+	setCreator(t, stub, "Org1MSP", testcerts.Alice)
+	alice, err := ownership.GetInvoker(stub)
+	require.NoError(t, err)
+	assert.Equal(t, "Org1MSP", alice.MSPID)
+	assert.NotEmpty(t, alice.ID)
+
+	owner, err := ownership.GetOwner(stub, key)
+	assert.NoError(t, err)
+	assert.Nil(t, owner, "key should not have an owner yet")
+	assert.EqualError(t, ownership.RequireOwner(stub, key), "key "+key+" has no owner")
+
+	// claim the key
+	assert.NoError(t, ownership.SetOwner(stub, key))
+	owner, err = ownership.GetOwner(stub, key)
+	assert.NoError(t, err)
+	assert.Equal(t, &alice, owner)
+	assert.NoError(t, ownership.RequireOwner(stub, key))
+	assert.NoError(t, ownership.SetOwner(stub, key), "owner should be able to claim the key again")
+
+	// another identity can neither modify nor claim the key
+	setCreator(t, stub, "Org2MSP", testcerts.Bob)
+	bob, err := ownership.GetInvoker(stub)
+	require.NoError(t, err)
+	err = ownership.RequireOwner(stub, key)
+	assert.Equal(t, &ownership.NotOwnerError{Key: key, Owner: alice, Caller: bob}, err)
+	assert.IsType(t, &ownership.NotOwnerError{}, ownership.SetOwner(stub, key))
+	assert.IsType(t, &ownership.NotOwnerError{}, ownership.TransferOwnership(stub, key, bob))
+	assert.IsType(t, &ownership.NotOwnerError{}, ownership.DeleteOwner(stub, key))
+
+	// the owner hands the key over
+	setCreator(t, stub, "Org1MSP", testcerts.Alice)
+	assert.EqualError(t, ownership.TransferOwnership(stub, key, ownership.Owner{}), "new owner of key "+key+" must have an MSP ID and an ID")
+	assert.NoError(t, ownership.TransferOwnership(stub, key, bob))
+	assert.IsType(t, &ownership.NotOwnerError{}, ownership.RequireOwner(stub, key))
+
+	setCreator(t, stub, "Org2MSP", testcerts.Bob)
+	assert.NoError(t, ownership.RequireOwner(stub, key))
+	assert.NoError(t, ownership.DeleteOwner(stub, key))
+	owner, err = ownership.GetOwner(stub, key)
+	assert.NoError(t, err)
+	assert.Nil(t, owner)
+}
+
+func TestOwnershipErrors(t *testing.T) {
+	state := map[string][]byte{}
+	stub := newStub(state)
+
+	_, err := ownership.GetOwner(stub, "")
+	assert.EqualError(t, err, "key must not be an empty string")
+
+	assert.Error(t, ownership.SetOwner(stub, "key1"), "SetOwner should fail without a creator")
+
+	stub.GetCreatorReturns([]byte("foo"), nil)
+	_, err = ownership.GetInvoker(stub)
+	assert.Error(t, err, "GetInvoker should fail with a malformed creator")
+
+	ownerKey, err := shim.CreateCompositeKey("github.com/hyperledger/fabric-chaincode-go/v2/pkg/ownership", []string{"6b657931"})
+	require.NoError(t, err)
+	state[ownerKey] = []byte("not json")
+	_, err = ownership.GetOwner(stub, "key1")
+	assert.ErrorContains(t, err, "failed to unmarshal owner of key key1")
+}
+
+// newStub returns a ChaincodeStub fake backed by state.
+func newStub(state map[string][]byte) *mocks.ChaincodeStub {
+	stub := &mocks.ChaincodeStub{}
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		return state[key], nil
+	}
+	stub.PutStateStub = func(key string, value []byte) error {
+		state[key] = value
+		return nil
+	}
+	stub.DelStateStub = func(key string) error {
+		delete(state, key)
+		return nil
+	}
+	stub.CreateCompositeKeyStub = shim.CreateCompositeKey
+	return stub
+}
+
+func setCreator(t *testing.T, stub *mocks.ChaincodeStub, mspID, cert string) {
+	creator, err := testcerts.Creator(mspID, cert)
+	require.NoError(t, err)
+	stub.GetCreatorReturns(creator, nil)
+}
@@ -0,0 +1,33 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package privacy
+
+// ChaincodeStubInterface is the subset of the chaincode stub used to store
+// and resolve pseudonyms.
+type ChaincodeStubInterface interface {
+	// GetCreator returns `SignatureHeader.Creator` (e.g. an identity)
+	// of the `SignedProposal`. This is the identity of the agent (or user)
+	// submitting the transaction.
+	GetCreator() ([]byte, error)
+
+	// GetPrivateData returns the value of the specified `key` from the
+	// specified `collection`.
+	GetPrivateData(collection, key string) ([]byte, error)
+
+	// PutPrivateData puts the specified `key` and `value` into the
+	// transaction's private writeset.
+	PutPrivateData(collection string, key string, value []byte) error
+
+	// PurgePrivateData records the specified `key` to be purged in the
+	// private writeset of the transaction.
+	PurgePrivateData(collection, key string) error
+
+	// CreateCompositeKey combines the given `attributes` to form a composite
+	// key.
+	CreateCompositeKey(objectType string, attributes []string) (string, error)
+}
+
+// AuthorizeFunc decides whether the identity submitting the transaction may
+// resolve pseudonyms. It returns an error if access is denied.
+type AuthorizeFunc func(stub ChaincodeStubInterface) error
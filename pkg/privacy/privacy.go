@@ -0,0 +1,119 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package privacy provides helpers to keep personal identifiers out of the
+// public world state.
+//
+// An identifier is replaced by a keyed hash (its pseudonym) which can be
+// written to public state, while the mapping from the pseudonym back to the
+// identifier is kept in a private data collection, under composite keys of an
+// object type reserved for this package. The mapping can be resolved by
+// authorized identities only and purged to forget the identifier.
+//
+// The salt must be the same on every endorsing peer and should not be
+// guessable, so it is usually supplied by the client in the transient map.
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+)
+
+// pseudonymObjectType is the object type of the composite keys pseudonym
+// mappings are stored under.
+const pseudonymObjectType = "github.com/hyperledger/fabric-chaincode-go/v2/pkg/privacy"
+
+// Pseudonym returns the hex encoded HMAC-SHA256 of the identifier, keyed with
+// the salt.
+func Pseudonym(identifier string, salt []byte) string {
+	h := hmac.New(sha256.New, salt)
+	h.Write([]byte(identifier))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Pseudonymize returns the pseudonym of identifier and records the mapping
+// from the pseudonym to identifier in the given private data collection.
+func Pseudonymize(stub ChaincodeStubInterface, collection, identifier string, salt []byte) (string, error) {
+	if identifier == "" {
+		return "", errors.New("identifier must not be an empty string")
+	}
+	if len(salt) == 0 {
+		return "", errors.New("salt must not be empty")
+	}
+
+	pseudonym := Pseudonym(identifier, salt)
+	key, err := stub.CreateCompositeKey(pseudonymObjectType, []string{pseudonym})
+	if err != nil {
+		return "", err
+	}
+	if err := stub.PutPrivateData(collection, key, []byte(identifier)); err != nil {
+		return "", fmt.Errorf("failed to store pseudonym mapping: %s", err)
+	}
+	return pseudonym, nil
+}
+
+// Resolve returns the identifier behind pseudonym, as recorded in the given
+// private data collection. The identity submitting the transaction must be
+// allowed by authorize. An error is returned if the pseudonym is unknown.
+func Resolve(stub ChaincodeStubInterface, collection, pseudonym string, authorize AuthorizeFunc) (string, error) {
+	if authorize == nil {
+		return "", errors.New("an authorization function must be provided")
+	}
+	if err := authorize(stub); err != nil {
+		return "", err
+	}
+
+	key, err := stub.CreateCompositeKey(pseudonymObjectType, []string{pseudonym})
+	if err != nil {
+		return "", err
+	}
+	identifier, err := stub.GetPrivateData(collection, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pseudonym mapping: %s", err)
+	}
+	if identifier == nil {
+		return "", fmt.Errorf("pseudonym %s is unknown", pseudonym)
+	}
+	return string(identifier), nil
+}
+
+// Forget purges the mapping of pseudonym from the given private data
+// collection, including its history, so that the pseudonym can no longer be
+// resolved. The identity submitting the transaction must be allowed by
+// authorize.
+func Forget(stub ChaincodeStubInterface, collection, pseudonym string, authorize AuthorizeFunc) error {
+	if authorize == nil {
+		return errors.New("an authorization function must be provided")
+	}
+	if err := authorize(stub); err != nil {
+		return err
+	}
+
+	key, err := stub.CreateCompositeKey(pseudonymObjectType, []string{pseudonym})
+	if err != nil {
+		return err
+	}
+	return stub.PurgePrivateData(collection, key)
+}
+
+// MSPAuthorizer returns an AuthorizeFunc that allows identities belonging to
+// any of the given MSPs.
+func MSPAuthorizer(mspIDs ...string) AuthorizeFunc {
+	return func(stub ChaincodeStubInterface) error {
+		mspID, err := cid.GetMSPID(stub)
+		if err != nil {
+			return err
+		}
+		for _, allowed := range mspIDs {
+			if mspID == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("identities of MSP %s are not authorized to resolve pseudonyms", mspID)
+	}
+}
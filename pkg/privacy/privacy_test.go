@@ -0,0 +1,95 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package privacy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/internal/testcerts"
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/privacy"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPseudonym(t *testing.T) {
+	p1 := privacy.Pseudonym("alice@example.com", []byte("salt1"))
+	assert.Len(t, p1, 64)
+	assert.Equal(t, p1, privacy.Pseudonym("alice@example.com", []byte("salt1")), "pseudonym should be deterministic")
+	assert.NotEqual(t, p1, privacy.Pseudonym("alice@example.com", []byte("salt2")))
+	assert.NotEqual(t, p1, privacy.Pseudonym("bob@example.com", []byte("salt1")))
+	assert.NotEqual(t, privacy.Pseudonym("c", []byte("ab")), privacy.Pseudonym("bc", []byte("a")), "moving bytes between salt and identifier should change the pseudonym")
+}
+
+func TestPseudonymizeAndResolve(t *testing.T) {
+	stub := newStub(t, "Org1MSP")
+
+	pseudonym, err := privacy.Pseudonymize(stub, "pii", "alice@example.com", []byte("salt"))
+	require.NoError(t, err)
+	assert.Equal(t, privacy.Pseudonym("alice@example.com", []byte("salt")), pseudonym)
+	expectedKey, err := shim.CreateCompositeKey("github.com/hyperledger/fabric-chaincode-go/v2/pkg/privacy", []string{pseudonym})
+	require.NoError(t, err)
+	collection, key, value := stub.PutPrivateDataArgsForCall(0)
+	assert.Equal(t, "pii", collection)
+	assert.Equal(t, expectedKey, key)
+	assert.Equal(t, []byte("alice@example.com"), value)
+
+	identifier, err := privacy.Resolve(stub, "pii", pseudonym, privacy.MSPAuthorizer("Org1MSP"))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", identifier)
+
+	_, err = privacy.Resolve(stub, "pii", pseudonym, privacy.MSPAuthorizer("Org2MSP"))
+	assert.EqualError(t, err, "identities of MSP Org1MSP are not authorized to resolve pseudonyms")
+	_, err = privacy.Resolve(stub, "pii", pseudonym, nil)
+	assert.EqualError(t, err, "an authorization function must be provided")
+	_, err = privacy.Resolve(stub, "other", pseudonym, privacy.MSPAuthorizer("Org1MSP"))
+	assert.EqualError(t, err, "pseudonym "+pseudonym+" is unknown")
+
+	assert.EqualError(t, privacy.Forget(stub, "pii", pseudonym, privacy.MSPAuthorizer("Org2MSP")), "identities of MSP Org1MSP are not authorized to resolve pseudonyms")
+	assert.NoError(t, privacy.Forget(stub, "pii", pseudonym, privacy.MSPAuthorizer("Org1MSP")))
+	_, err = privacy.Resolve(stub, "pii", pseudonym, privacy.MSPAuthorizer("Org1MSP"))
+	assert.EqualError(t, err, "pseudonym "+pseudonym+" is unknown")
+}
+
+func TestPseudonymizeErrors(t *testing.T) {
+	stub := newStub(t, "Org1MSP")
+
+	_, err := privacy.Pseudonymize(stub, "pii", "", []byte("salt"))
+	assert.EqualError(t, err, "identifier must not be an empty string")
+	_, err = privacy.Pseudonymize(stub, "pii", "alice@example.com", nil)
+	assert.EqualError(t, err, "salt must not be empty")
+
+	stub.PutPrivateDataReturns(errors.New("boom"))
+	stub.GetPrivateDataReturns(nil, errors.New("boom"))
+	_, err = privacy.Pseudonymize(stub, "pii", "alice@example.com", []byte("salt"))
+	assert.EqualError(t, err, "failed to store pseudonym mapping: boom")
+	_, err = privacy.Resolve(stub, "pii", "pseudonym", privacy.MSPAuthorizer("Org1MSP"))
+	assert.EqualError(t, err, "failed to read pseudonym mapping: boom")
+}
+
+// newStub returns a ChaincodeStub fake for a client of mspID, backed by an
+// in-memory private data store.
+func newStub(t *testing.T, mspID string) *mocks.ChaincodeStub {
+	creator, err := testcerts.Creator(mspID, testcerts.Alice)
+	require.NoError(t, err)
+
+	data := map[string][]byte{}
+	stub := &mocks.ChaincodeStub{}
+	stub.GetCreatorReturns(creator, nil)
+	stub.GetPrivateDataStub = func(collection, key string) ([]byte, error) {
+		return data[collection+"/"+key], nil
+	}
+	stub.PutPrivateDataStub = func(collection, key string, value []byte) error {
+		data[collection+"/"+key] = value
+		return nil
+	}
+	stub.PurgePrivateDataStub = func(collection, key string) error {
+		delete(data, collection+"/"+key)
+		return nil
+	}
+	stub.CreateCompositeKeyStub = shim.CreateCompositeKey
+	return stub
+}
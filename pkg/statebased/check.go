@@ -0,0 +1,77 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package statebased
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+)
+
+// CheckOrg returns an OrgNotInPolicyError if the org with the given MSP ID is
+// not one of the orgs required by the serialized key-level endorsement
+// policy. An empty policy requires no orgs and always passes the check.
+func CheckOrg(policy []byte, mspID string) error {
+	if len(policy) == 0 {
+		return nil
+	}
+
+	ep, err := NewStateEP(policy)
+	if err != nil {
+		return err
+	}
+
+	orgs := ep.ListOrgs()
+	sort.Strings(orgs)
+	for _, org := range orgs {
+		if org == mspID {
+			return nil
+		}
+	}
+
+	return &OrgNotInPolicyError{Org: mspID, Orgs: orgs}
+}
+
+// CheckSubmitterOrg verifies that the org of the identity submitting the
+// transaction is one of the orgs required by the key-level endorsement
+// policy of `key`, for chaincode that only lets orgs listed in a key's policy
+// change it. The check is made on the submitter only: whether the
+// transaction satisfies the policy is still decided at validation time by
+// the orgs of the endorsing peers. Keys without a key-level endorsement
+// policy always pass.
+// Note that this introduces a read dependency on `key` in the transaction's
+// readset.
+func CheckSubmitterOrg(stub ChaincodeStubInterface, key string) error {
+	policy, err := stub.GetStateValidationParameter(key)
+	if err != nil {
+		return fmt.Errorf("failed to get endorsement policy of key %s: %s", key, err)
+	}
+	return checkSubmitterOrg(stub, key, policy)
+}
+
+// CheckSubmitterOrgForPrivateData verifies that the org of the identity
+// submitting the transaction is one of the orgs required by the key-level
+// endorsement policy of the private data specified by `key`. See
+// CheckSubmitterOrg for details.
+func CheckSubmitterOrgForPrivateData(stub ChaincodeStubInterface, collection, key string) error {
+	policy, err := stub.GetPrivateDataValidationParameter(collection, key)
+	if err != nil {
+		return fmt.Errorf("failed to get endorsement policy of key %s in collection %s: %s", key, collection, err)
+	}
+	return checkSubmitterOrg(stub, key, policy)
+}
+
+func checkSubmitterOrg(stub ChaincodeStubInterface, key string, policy []byte) error {
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return err
+	}
+
+	err = CheckOrg(policy, mspID)
+	if notInPolicy, ok := err.(*OrgNotInPolicyError); ok {
+		notInPolicy.Key = key
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package statebased_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/internal/testcerts"
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/statebased"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCheckOrg(t *testing.T) {
+	ep, err := statebased.NewStateEP(nil)
+	require.NoError(t, err)
+	require.NoError(t, ep.AddOrgs(statebased.RoleTypePeer, "Org2", "Org1"))
+	policy, err := ep.Policy()
+	require.NoError(t, err)
+
+	assert.NoError(t, statebased.CheckOrg(nil, "Org3"), "empty policy should not require any org")
+	assert.NoError(t, statebased.CheckOrg(policy, "Org1"))
+	err = statebased.CheckOrg(policy, "Org3")
+	assert.Equal(t, &statebased.OrgNotInPolicyError{Org: "Org3", Orgs: []string{"Org1", "Org2"}}, err)
+	assert.EqualError(t, err, "org Org3 is not one of the orgs required to endorse changes: [Org1 Org2]")
+
+	assert.ErrorContains(t, statebased.CheckOrg([]byte("garbage"), "Org1"), "Error unmarshaling to SignaturePolicy")
+}
+
+func TestCheckSubmitterOrg(t *testing.T) {
+	policy, err := proto.Marshal(signedByMspPeer("Org1", t))
+	require.NoError(t, err)
+	creator, err := testcerts.Creator("Org1", testcerts.Alice)
+	require.NoError(t, err)
+
+	policies := map[string][]byte{"key1": policy, "col/key1": policy}
+	stub := &mocks.ChaincodeStub{}
+	stub.GetCreatorReturns(creator, nil)
+	stub.GetStateValidationParameterStub = func(key string) ([]byte, error) {
+		return policies[key], nil
+	}
+	stub.GetPrivateDataValidationParameterStub = func(collection, key string) ([]byte, error) {
+		return policies[collection+"/"+key], nil
+	}
+	assert.NoError(t, statebased.CheckSubmitterOrg(stub, "key1"))
+	assert.NoError(t, statebased.CheckSubmitterOrg(stub, "key2"), "key without policy should pass")
+	assert.NoError(t, statebased.CheckSubmitterOrgForPrivateData(stub, "col", "key1"))
+
+	creator, err = testcerts.Creator("Org2", testcerts.Alice)
+	require.NoError(t, err)
+	stub.GetCreatorReturns(creator, nil)
+	err = statebased.CheckSubmitterOrg(stub, "key1")
+	assert.EqualError(t, err, "org Org2 is not one of the orgs required to endorse changes to key key1: [Org1]")
+	err = statebased.CheckSubmitterOrgForPrivateData(stub, "col", "key1")
+	assert.IsType(t, &statebased.OrgNotInPolicyError{}, err)
+
+	stub.GetStateValidationParameterReturns(nil, errors.New("boom"))
+	stub.GetPrivateDataValidationParameterReturns(nil, errors.New("boom"))
+	assert.EqualError(t, statebased.CheckSubmitterOrg(stub, "key1"), "failed to get endorsement policy of key key1: boom")
+	assert.EqualError(t, statebased.CheckSubmitterOrgForPrivateData(stub, "col", "key1"), "failed to get endorsement policy of key key1 in collection col: boom")
+
+	stub.GetStateValidationParameterReturns(policy, nil)
+	stub.GetCreatorReturns([]byte("garbage"), nil)
+	assert.Error(t, statebased.CheckSubmitterOrg(stub, "key1"))
+}
@@ -0,0 +1,131 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package statebased
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// OrgRole is an org required by a key-level endorsement policy, along with
+// the role its endorsing identities must have.
+type OrgRole struct {
+	MSPID string
+	// Role is RoleTypeMember or RoleTypePeer for policies built with a
+	// KeyEndorsementPolicy. Policies built otherwise may use other MSP roles,
+	// such as "ADMIN" or "CLIENT", which cannot be passed to AddOrgs.
+	Role RoleType
+}
+
+// PolicyDiff describes the changes that turn one key-level endorsement policy
+// into another.
+type PolicyDiff struct {
+	// Added are the orgs to add, including orgs whose role changes
+	Added []OrgRole
+	// Removed are the MSP IDs of the orgs to delete
+	Removed []string
+	// RuleChanged is true if the policies differ in more than their orgs,
+	// for example because one of them requires only some of its orgs to
+	// endorse. Such a change cannot be made by adding and deleting orgs.
+	RuleChanged bool
+}
+
+// Empty returns true if the policies are equivalent and no update is needed.
+func (d *PolicyDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && !d.RuleChanged
+}
+
+// GetKeyPolicy returns the key-level endorsement policy of `key`. If the key
+// has no key-level endorsement policy, an empty policy is returned.
+func GetKeyPolicy(stub ChaincodeStubInterface, key string) (KeyEndorsementPolicy, error) {
+	policy, err := stub.GetStateValidationParameter(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endorsement policy of key %s: %s", key, err)
+	}
+	return NewStateEP(policy)
+}
+
+// GetPrivateDataKeyPolicy returns the key-level endorsement policy of the
+// private data specified by `key`. If the key has no key-level endorsement
+// policy, an empty policy is returned.
+func GetPrivateDataKeyPolicy(stub ChaincodeStubInterface, collection, key string) (KeyEndorsementPolicy, error) {
+	policy, err := stub.GetPrivateDataValidationParameter(collection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endorsement policy of key %s in collection %s: %s", key, collection, err)
+	}
+	return NewStateEP(policy)
+}
+
+// ListOrgRoles returns the orgs required by the serialized key-level
+// endorsement policy with their roles, sorted by MSP ID.
+func ListOrgRoles(policy []byte) ([]OrgRole, error) {
+	s, err := newStateEP(policy)
+	if err != nil {
+		return nil, err
+	}
+	return s.orgRoles(), nil
+}
+
+// DiffPolicies compares the serialized key-level endorsement policies
+// `current` and `desired`, and returns the orgs to add to and delete from
+// `current` to obtain `desired`. The orgs are compared by MSP ID and role, and
+// RuleChanged is set if either policy is not the one a KeyEndorsementPolicy
+// builds from its orgs. An empty diff means that updating the policy is not
+// needed.
+func DiffPolicies(current, desired []byte) (*PolicyDiff, error) {
+	currentEP, err := newStateEP(current)
+	if err != nil {
+		return nil, err
+	}
+	desiredEP, err := newStateEP(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &PolicyDiff{
+		RuleChanged: !bytes.Equal(current, desired) && (!currentEP.builds(current) || !desiredEP.builds(desired)),
+	}
+	for _, orgRole := range desiredEP.orgRoles() {
+		role, ok := currentEP.orgs[orgRole.MSPID]
+		if !ok || role != desiredEP.orgs[orgRole.MSPID] {
+			diff.Added = append(diff.Added, orgRole)
+		}
+	}
+	for _, orgRole := range currentEP.orgRoles() {
+		if _, ok := desiredEP.orgs[orgRole.MSPID]; !ok {
+			diff.Removed = append(diff.Removed, orgRole.MSPID)
+		}
+	}
+	return diff, nil
+}
+
+// builds returns true if policy is the policy built from the orgs of s.
+func (s *stateEP) builds(policy []byte) bool {
+	if len(policy) == 0 {
+		return true
+	}
+	spe := &common.SignaturePolicyEnvelope{}
+	if err := proto.Unmarshal(policy, spe); err != nil {
+		return false
+	}
+	built, err := s.policyFromMSPIDs()
+	if err != nil {
+		return false
+	}
+	return proto.Equal(spe, built)
+}
+
+func (s *stateEP) orgRoles() []OrgRole {
+	mspids := s.ListOrgs()
+	sort.Strings(mspids)
+	orgRoles := make([]OrgRole, len(mspids))
+	for i, mspid := range mspids {
+		orgRoles[i] = OrgRole{MSPID: mspid, Role: RoleType(s.orgs[mspid].String())}
+	}
+	return orgRoles
+}
@@ -0,0 +1,153 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package statebased_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/statebased"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim/mocks"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func newPolicy(t *testing.T, role statebased.RoleType, orgs ...string) []byte {
+	ep, err := statebased.NewStateEP(nil)
+	require.NoError(t, err)
+	require.NoError(t, ep.AddOrgs(role, orgs...))
+	policy, err := ep.Policy()
+	require.NoError(t, err)
+	return policy
+}
+
+// nOutOfPolicy returns a policy that requires n of the orgs to endorse with
+// the given role.
+func nOutOfPolicy(t *testing.T, n int32, role msp.MSPRole_MSPRoleType, orgs ...string) []byte {
+	principals := make([]*msp.MSPPrincipal, len(orgs))
+	rules := make([]*common.SignaturePolicy, len(orgs))
+	for i, org := range orgs {
+		principal, err := proto.Marshal(&msp.MSPRole{Role: role, MspIdentifier: org})
+		require.NoError(t, err)
+		principals[i] = &msp.MSPPrincipal{PrincipalClassification: msp.MSPPrincipal_ROLE, Principal: principal}
+		rules[i] = &common.SignaturePolicy{Type: &common.SignaturePolicy_SignedBy{SignedBy: int32(i)}}
+	}
+	policy, err := proto.Marshal(&common.SignaturePolicyEnvelope{
+		Rule: &common.SignaturePolicy{
+			Type: &common.SignaturePolicy_NOutOf_{
+				NOutOf: &common.SignaturePolicy_NOutOf{N: n, Rules: rules},
+			},
+		},
+		Identities: principals,
+	})
+	require.NoError(t, err)
+	return policy
+}
+
+func TestListOrgRoles(t *testing.T) {
+	orgRoles, err := statebased.ListOrgRoles(newPolicy(t, statebased.RoleTypePeer, "Org2", "Org1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []statebased.OrgRole{
+		{MSPID: "Org1", Role: statebased.RoleTypePeer},
+		{MSPID: "Org2", Role: statebased.RoleTypePeer},
+	}, orgRoles)
+
+	orgRoles, err = statebased.ListOrgRoles(nOutOfPolicy(t, 1, msp.MSPRole_ADMIN, "Org1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []statebased.OrgRole{{MSPID: "Org1", Role: "ADMIN"}}, orgRoles)
+
+	orgRoles, err = statebased.ListOrgRoles(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, orgRoles)
+
+	_, err = statebased.ListOrgRoles([]byte("garbage"))
+	assert.ErrorContains(t, err, "Error unmarshaling to SignaturePolicy")
+}
+
+func TestDiffPolicies(t *testing.T) {
+	current := newPolicy(t, statebased.RoleTypePeer, "Org1", "Org2")
+
+	diff, err := statebased.DiffPolicies(current, newPolicy(t, statebased.RoleTypePeer, "Org2", "Org1"))
+	assert.NoError(t, err)
+	assert.True(t, diff.Empty(), "equivalent policies should not need an update")
+
+	diff, err = statebased.DiffPolicies(current, nOutOfPolicy(t, 2, msp.MSPRole_PEER, "Org1", "Org2"))
+	assert.NoError(t, err)
+	assert.True(t, diff.Empty(), "a policy requiring all of its orgs should match one built from them")
+
+	anyOrg := nOutOfPolicy(t, 1, msp.MSPRole_PEER, "Org1", "Org2")
+	diff, err = statebased.DiffPolicies(anyOrg, current)
+	assert.NoError(t, err)
+	assert.False(t, diff.Empty(), "a policy requiring one of the orgs should differ from one requiring all of them")
+	assert.True(t, diff.RuleChanged)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+
+	diff, err = statebased.DiffPolicies(current, anyOrg)
+	assert.NoError(t, err)
+	assert.True(t, diff.RuleChanged)
+
+	diff, err = statebased.DiffPolicies(anyOrg, anyOrg)
+	assert.NoError(t, err)
+	assert.True(t, diff.Empty(), "identical policies should not need an update")
+
+	desired, err := statebased.NewStateEP(nil)
+	require.NoError(t, err)
+	require.NoError(t, desired.AddOrgs(statebased.RoleTypePeer, "Org2"))
+	require.NoError(t, desired.AddOrgs(statebased.RoleTypeMember, "Org1", "Org3"))
+	desiredPolicy, err := desired.Policy()
+	require.NoError(t, err)
+
+	diff, err = statebased.DiffPolicies(current, desiredPolicy)
+	assert.NoError(t, err)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, []statebased.OrgRole{
+		{MSPID: "Org1", Role: statebased.RoleTypeMember},
+		{MSPID: "Org3", Role: statebased.RoleTypeMember},
+	}, diff.Added)
+	assert.Empty(t, diff.Removed)
+
+	diff, err = statebased.DiffPolicies(current, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, diff.Added)
+	assert.Equal(t, []string{"Org1", "Org2"}, diff.Removed)
+
+	_, err = statebased.DiffPolicies([]byte("garbage"), current)
+	assert.Error(t, err)
+	_, err = statebased.DiffPolicies(current, []byte("garbage"))
+	assert.Error(t, err)
+}
+
+func TestGetKeyPolicy(t *testing.T) {
+	policy := newPolicy(t, statebased.RoleTypePeer, "Org1")
+	stub := &mocks.ChaincodeStub{}
+	stub.GetStateValidationParameterReturnsOnCall(0, policy, nil)
+	stub.GetPrivateDataValidationParameterReturns(policy, nil)
+
+	ep, err := statebased.GetKeyPolicy(stub, "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org1"}, ep.ListOrgs())
+	assert.Equal(t, "key1", stub.GetStateValidationParameterArgsForCall(0))
+
+	ep, err = statebased.GetKeyPolicy(stub, "key2")
+	assert.NoError(t, err)
+	assert.Empty(t, ep.ListOrgs(), "key without policy should return an empty policy")
+
+	ep, err = statebased.GetPrivateDataKeyPolicy(stub, "col", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org1"}, ep.ListOrgs())
+	collection, key := stub.GetPrivateDataValidationParameterArgsForCall(0)
+	assert.Equal(t, "col", collection)
+	assert.Equal(t, "key1", key)
+
+	stub.GetStateValidationParameterReturns(nil, errors.New("boom"))
+	stub.GetPrivateDataValidationParameterReturns(nil, errors.New("boom"))
+	_, err = statebased.GetKeyPolicy(stub, "key1")
+	assert.EqualError(t, err, "failed to get endorsement policy of key key1: boom")
+	_, err = statebased.GetPrivateDataKeyPolicy(stub, "col", "key1")
+	assert.EqualError(t, err, "failed to get endorsement policy of key key1 in collection col: boom")
+}
@@ -49,3 +49,38 @@ type KeyEndorsementPolicy interface {
 	// ListOrgs returns an array of channel orgs that are required to endorse changes.
 	ListOrgs() []string
 }
+
+// OrgNotInPolicyError is returned when an org is not one of the orgs
+// required by a key-level endorsement policy.
+type OrgNotInPolicyError struct {
+	// Key is the key the policy applies to, if known
+	Key string
+	// Org is the MSP ID of the org that was checked
+	Org string
+	// Orgs are the MSP IDs of the orgs required by the policy
+	Orgs []string
+}
+
+func (e *OrgNotInPolicyError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("org %s is not one of the orgs required to endorse changes: %v", e.Org, e.Orgs)
+	}
+	return fmt.Sprintf("org %s is not one of the orgs required to endorse changes to key %s: %v", e.Org, e.Key, e.Orgs)
+}
+
+// ChaincodeStubInterface is the subset of the chaincode stub used to check
+// the key-level endorsement policy of a key before writing it.
+type ChaincodeStubInterface interface {
+	// GetCreator returns `SignatureHeader.Creator` (e.g. an identity)
+	// of the `SignedProposal`. This is the identity of the agent (or user)
+	// submitting the transaction.
+	GetCreator() ([]byte, error)
+
+	// GetStateValidationParameter retrieves the key-level endorsement policy
+	// for `key`.
+	GetStateValidationParameter(key string) ([]byte, error)
+
+	// GetPrivateDataValidationParameter retrieves the key-level endorsement
+	// policy for the private data specified by `key`.
+	GetPrivateDataValidationParameter(collection, key string) ([]byte, error)
+}
@@ -20,6 +20,10 @@ type stateEP struct {
 // NewStateEP constructs a state-based endorsement policy from a given
 // serialized EP byte array. If the byte array is empty, a new EP is created.
 func NewStateEP(policy []byte) (KeyEndorsementPolicy, error) {
+	return newStateEP(policy)
+}
+
+func newStateEP(policy []byte) (*stateEP, error) {
 	s := &stateEP{orgs: make(map[string]msp.MSPRole_MSPRoleType)}
 	if policy != nil {
 		spe := &common.SignaturePolicyEnvelope{}
@@ -4,6 +4,8 @@
 package shim
 
 import (
+	"sync"
+
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
 )
 
@@ -14,6 +16,19 @@ const (
 	metadataKeyType
 )
 
+// maxPooledWriteBatchSize is the number of records above which a write batch
+// is not returned to the pool, so that a single large transaction does not
+// pin its map memory for the lifetime of the chaincode.
+const maxPooledWriteBatchSize = 1000
+
+var writeBatchPool = sync.Pool{
+	New: func() interface{} {
+		return &writeBatch{
+			writes: make(map[batchKey]*peer.WriteRecord),
+		}
+	},
+}
+
 type batchKey struct {
 	Collection string
 	Key        string
@@ -25,9 +40,18 @@ type writeBatch struct {
 }
 
 func newWriteBatch() *writeBatch {
-	return &writeBatch{
-		writes: make(map[batchKey]*peer.WriteRecord),
+	return writeBatchPool.Get().(*writeBatch)
+}
+
+// release clears the batch and returns it to the pool. The batch must not be
+// used after it has been released.
+func (b *writeBatch) release() {
+	if b == nil || len(b.writes) > maxPooledWriteBatchSize {
+		return
 	}
+
+	clear(b.writes)
+	writeBatchPool.Put(b)
 }
 
 func (b *writeBatch) Writes() []*peer.WriteRecord {
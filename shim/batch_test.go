@@ -0,0 +1,62 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package shim
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteBatchWrites(t *testing.T) {
+	t.Parallel()
+
+	b := newWriteBatch()
+	b.PutState("", "key3", []byte("value3"))
+	b.PutStateMetadataEntry("", "key1", "mkey", []byte("metavalue"))
+	b.PutState("col", "key0", []byte("private"))
+	b.DelState("", "key2")
+	b.PutState("", "key1", []byte("value1"))
+	b.PurgeState("col", "key0")
+
+	type record struct {
+		collection string
+		key        string
+		recordType peer.WriteRecord_Type
+	}
+	var actual []record
+	for _, w := range b.Writes() {
+		actual = append(actual, record{w.GetCollection(), w.GetKey(), w.GetType()})
+	}
+
+	assert.ElementsMatch(t, []record{
+		{"", "key1", peer.WriteRecord_PUT_STATE},
+		{"", "key1", peer.WriteRecord_PUT_STATE_METADATA},
+		{"", "key2", peer.WriteRecord_DEL_STATE},
+		{"", "key3", peer.WriteRecord_PUT_STATE},
+		{"col", "key0", peer.WriteRecord_PURGE_PRIVATE_DATA},
+	}, actual)
+}
+
+func TestWriteBatchWritesNil(t *testing.T) {
+	t.Parallel()
+
+	var b *writeBatch
+	assert.Nil(t, b.Writes())
+}
+
+func TestWriteBatchRelease(t *testing.T) {
+	t.Parallel()
+
+	b := newWriteBatch()
+	b.PutState("", "key", []byte("value"))
+	b.release()
+
+	b = newWriteBatch()
+	assert.Empty(t, b.Writes(), "batch from the pool should be empty")
+
+	var nilBatch *writeBatch
+	nilBatch.release()
+}
@@ -34,11 +34,13 @@ type ChaincodeServer struct {
 	TLSProps TLSProperties
 	// KaOpts keepalive options, sensible defaults provided if nil
 	KaOpts *keepalive.ServerParameters
+	// Interceptors observe the messages exchanged with the peer
+	Interceptors []MessageInterceptor
 }
 
 // Connect the bidi stream entry point called by chaincode to register with the Peer.
 func (cs *ChaincodeServer) Connect(stream peer.Chaincode_ConnectServer) error {
-	return chatWithPeer(cs.CCID, stream, cs.CC)
+	return chatWithPeer(cs.CCID, stream, cs.CC, cs.Interceptors...)
 }
 
 // Start the server
@@ -6,6 +6,8 @@ package shim
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"sync"
 
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
@@ -58,6 +60,13 @@ type Handler struct {
 	// concurrent requests to the peer
 	responseChannelsMutex sync.Mutex
 	responseChannels      map[string]chan *peer.ChaincodeMessage
+
+	// iteratorLeakDetection enables logging of the query iterators left open
+	// by the chaincode, along with the call site that created them.
+	iteratorLeakDetection bool
+
+	// interceptors observe the messages exchanged with the peer.
+	interceptors []MessageInterceptor
 }
 
 func shorttxid(txid string) string {
@@ -67,11 +76,13 @@ func shorttxid(txid string) string {
 	return txid[0:8]
 }
 
-// serialSend serializes calls to Send on the gRPC client.
+// serialSend serializes calls to Send on the gRPC client. Interceptors are
+// called under the same lock so that they observe messages in send order.
 func (h *Handler) serialSend(msg *peer.ChaincodeMessage) error {
 	h.serialLock.Lock()
 	defer h.serialLock.Unlock()
 
+	h.messageSent(msg)
 	return h.chatStream.Send(msg)
 }
 
@@ -149,15 +160,24 @@ func (h *Handler) sendReceive(msg *peer.ChaincodeMessage, responseChan <-chan *p
 }
 
 // NewChaincodeHandler returns a new instance of the shim side handler.
-func newChaincodeHandler(peerChatStream PeerChaincodeStream, chaincode Chaincode) *Handler {
+func newChaincodeHandler(peerChatStream PeerChaincodeStream, chaincode Chaincode, interceptors ...MessageInterceptor) *Handler {
 	return &Handler{
-		chatStream:       peerChatStream,
-		cc:               chaincode,
-		responseChannels: map[string]chan *peer.ChaincodeMessage{},
-		state:            created,
+		chatStream:            peerChatStream,
+		cc:                    chaincode,
+		responseChannels:      map[string]chan *peer.ChaincodeMessage{},
+		state:                 created,
+		iteratorLeakDetection: iteratorLeakDetectionEnabled(),
+		interceptors:          interceptors,
 	}
 }
 
+// iteratorLeakDetectionEnabled checks the CORE_CHAINCODE_ITERATOR_LEAK_DETECTION
+// env var. Leak detection is disabled if the env var is not set or invalid.
+func iteratorLeakDetectionEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("CORE_CHAINCODE_ITERATOR_LEAK_DETECTION"))
+	return err == nil && enabled
+}
+
 type stubHandlerFunc func(*peer.ChaincodeMessage) (*peer.ChaincodeMessage, error)
 
 func (h *Handler) handleStubInteraction(handler stubHandlerFunc, msg *peer.ChaincodeMessage, errc chan<- error) {
@@ -184,6 +204,7 @@ func (h *Handler) handleInit(msg *peer.ChaincodeMessage) (*peer.ChaincodeMessage
 	}
 
 	res := h.cc.Init(stub)
+	stub.closeIterators()
 	if res.Status >= ERROR {
 		return &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_ERROR, Payload: []byte(res.Message), Txid: msg.Txid, ChaincodeEvent: stub.chaincodeEvent, ChannelId: msg.ChannelId}, nil
 	}
@@ -216,6 +237,7 @@ func (h *Handler) handleTransaction(msg *peer.ChaincodeMessage) (*peer.Chaincode
 	}
 
 	res := h.cc.Invoke(stub)
+	stub.closeIterators()
 
 	// Endorser will handle error contained in Response.
 	resBytes, err := proto.Marshal(res)
@@ -748,6 +770,8 @@ func (h *Handler) handleCreated(msg *peer.ChaincodeMessage) error {
 
 // handleMessage message handles loop for shim side of chaincode/peer stream.
 func (h *Handler) handleMessage(msg *peer.ChaincodeMessage, errc chan error) error {
+	h.messageReceived(msg)
+
 	if msg.Type == peer.ChaincodeMessage_KEEPALIVE {
 		h.serialSendAsync(msg, errc)
 		return nil
@@ -4,8 +4,11 @@
 package shim
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/v2/shim/internal/mock"
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
@@ -296,3 +299,83 @@ func TestHandlePeerCalls(t *testing.T) {
 	assert.ErrorContains(t, err, "cannot create response channel")
 
 }
+
+func TestSentPayloadsAreNotReused(t *testing.T) {
+	h := &Handler{
+		cc:               &mockChaincode{},
+		responseChannels: map[string]chan *peer.ChaincodeMessage{},
+		state:            ready,
+	}
+	chatStream := &mock.PeerChaincodeStream{}
+	chatStream.SendStub = func(msg *peer.ChaincodeMessage) error {
+		go func() {
+			err := h.handleResponse(&peer.ChaincodeMessage{
+				Type:      peer.ChaincodeMessage_RESPONSE,
+				ChannelId: msg.GetChannelId(),
+				Txid:      msg.GetTxid(),
+			})
+			assert.NoError(t, err, "handleResponse")
+		}()
+		return nil
+	}
+	h.chatStream = chatStream
+
+	_, err := h.handleGetState("", "key1", "channel", "txid")
+	assert.NoError(t, err)
+	first := chatStream.SendArgsForCall(0)
+	expected := marshalOrPanic(&peer.GetState{Key: "key1"})
+	assert.Equal(t, expected, first.GetPayload())
+
+	_, err = h.handleGetState("", "key2", "channel", "txid")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, first.GetPayload(), "a sent message should not be modified by later requests")
+}
+
+type recordingInterceptor struct {
+	mutex    sync.Mutex
+	received []peer.ChaincodeMessage_Type
+	sent     []peer.ChaincodeMessage_Type
+}
+
+func (ri *recordingInterceptor) MessageReceived(msg *peer.ChaincodeMessage) {
+	ri.mutex.Lock()
+	defer ri.mutex.Unlock()
+	ri.received = append(ri.received, msg.GetType())
+}
+
+func (ri *recordingInterceptor) MessageSent(msg *peer.ChaincodeMessage) {
+	ri.mutex.Lock()
+	defer ri.mutex.Unlock()
+	ri.sent = append(ri.sent, msg.GetType())
+}
+
+func TestMessageInterceptors(t *testing.T) {
+	t.Parallel()
+
+	chatStream := &mock.PeerChaincodeStream{}
+	sendErr := errors.New("send failed")
+	chatStream.SendStub = func(msg *peer.ChaincodeMessage) error {
+		if msg.GetType() == peer.ChaincodeMessage_GET_STATE {
+			return sendErr
+		}
+		return nil
+	}
+	first := &recordingInterceptor{}
+	second := &recordingInterceptor{}
+
+	handler := newChaincodeHandler(chatStream, &mockChaincode{}, first, second)
+	handler.state = ready
+
+	err := handler.handleMessage(&peer.ChaincodeMessage{Type: peer.ChaincodeMessage_INIT}, nil)
+	assert.NoError(t, err)
+	err = handler.serialSend(&peer.ChaincodeMessage{Type: peer.ChaincodeMessage_GET_STATE})
+	assert.EqualError(t, err, "send failed")
+
+	assert.Eventually(t, func() bool { return chatStream.SendCallCount() == 2 }, time.Second, 10*time.Millisecond)
+	for _, ri := range []*recordingInterceptor{first, second} {
+		ri.mutex.Lock()
+		assert.Equal(t, []peer.ChaincodeMessage_Type{peer.ChaincodeMessage_INIT}, ri.received)
+		assert.ElementsMatch(t, []peer.ChaincodeMessage_Type{peer.ChaincodeMessage_COMPLETED, peer.ChaincodeMessage_GET_STATE}, ri.sent)
+		ri.mutex.Unlock()
+	}
+}
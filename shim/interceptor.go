@@ -0,0 +1,38 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package shim
+
+import "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+
+// MessageInterceptor observes the raw messages exchanged between the chaincode
+// and the peer, for example to debug the protocol, capture messages or measure
+// latency by message type.
+//
+// Interceptors are called on the message path, so they should return quickly.
+// MessageSent is called while the handler holds the lock that serializes
+// sends, so that messages are observed in the order they are sent; a slow
+// interceptor, such as one writing to a slow io.Writer, delays every message
+// sent to the peer. Interceptors must not modify the messages; use
+// proto.Clone to get a copy that can be modified.
+type MessageInterceptor interface {
+	// MessageReceived is called for every message received from the peer,
+	// before it is handled.
+	MessageReceived(msg *peer.ChaincodeMessage)
+
+	// MessageSent is called for every message sent to the peer, just before
+	// it is handed to the stream, so that it is observed before any reply.
+	MessageSent(msg *peer.ChaincodeMessage)
+}
+
+func (h *Handler) messageReceived(msg *peer.ChaincodeMessage) {
+	for _, interceptor := range h.interceptors {
+		interceptor.MessageReceived(msg)
+	}
+}
+
+func (h *Handler) messageSent(msg *peer.ChaincodeMessage) {
+	for _, interceptor := range h.interceptors {
+		interceptor.MessageSent(msg)
+	}
+}
@@ -23,9 +23,18 @@ type Chaincode interface {
 	Invoke(stub ChaincodeStubInterface) *peer.Response
 }
 
+//go:generate counterfeiter -o mocks/chaincode_stub.go --fake-name ChaincodeStub . ChaincodeStubInterface
+
 // ChaincodeStubInterface is used by deployable chaincode apps to access and
 // modify their ledgers
 type ChaincodeStubInterface interface {
+	// The state and private data APIs are grouped in the embedded interfaces,
+	// so that code and mocks can depend on just the capabilities they use.
+	StateReader
+	StateWriter
+	QueryExecutor
+	PrivateDataAccessor
+
 	// GetArgs returns the arguments intended for the chaincode Init and Invoke
 	// as an array of byte arrays.
 	GetArgs() [][]byte
@@ -73,6 +82,64 @@ type ChaincodeStubInterface interface {
 	// If `channel` is empty, the caller's channel is assumed.
 	InvokeChaincode(chaincodeName string, args [][]byte, channel string) *peer.Response
 
+	// CreateCompositeKey combines the given `attributes` to form a composite
+	// key. The objectType and attributes are expected to have only valid utf8
+	// strings and should not contain U+0000 (nil byte) and U+10FFFF
+	// (biggest and unallocated code point).
+	// The resulting composite key can be used as the key in PutState().
+	CreateCompositeKey(objectType string, attributes []string) (string, error)
+
+	// SplitCompositeKey splits the specified key into attributes on which the
+	// composite key was formed. Composite keys found during range queries
+	// or partial composite key queries can therefore be split into their
+	// composite parts.
+	SplitCompositeKey(compositeKey string) (string, []string, error)
+
+	// GetCreator returns `SignatureHeader.Creator` (e.g. an identity)
+	// of the `SignedProposal`. This is the identity of the agent (or user)
+	// submitting the transaction.
+	GetCreator() ([]byte, error)
+
+	// GetTransient returns the `ChaincodeProposalPayload.Transient` field.
+	// It is a map that contains data (e.g. cryptographic material)
+	// that might be used to implement some form of application-level
+	// confidentiality. The contents of this field, as prescribed by
+	// `ChaincodeProposalPayload`, are supposed to always
+	// be omitted from the transaction and excluded from the ledger.
+	GetTransient() (map[string][]byte, error)
+
+	// GetBinding returns the transaction binding, which is used to enforce a
+	// link between application data (like those stored in the transient field
+	// above) to the proposal itself. This is useful to avoid possible replay
+	// attacks.
+	GetBinding() ([]byte, error)
+
+	// GetDecorations returns additional data (if applicable) about the proposal
+	// that originated from the peer. This data is set by the decorators of the
+	// peer, which append or mutate the chaincode input passed to the chaincode.
+	GetDecorations() map[string][]byte
+
+	// GetSignedProposal returns the SignedProposal object, which contains all
+	// data elements part of a transaction proposal.
+	GetSignedProposal() (*peer.SignedProposal, error)
+
+	// GetTxTimestamp returns the timestamp when the transaction was created. This
+	// is taken from the transaction ChannelHeader, therefore it will indicate the
+	// client's timestamp and will have the same value across all endorsers.
+	GetTxTimestamp() (*timestamppb.Timestamp, error)
+
+	// SetEvent allows the chaincode to set an event on the response to the
+	// proposal to be included as part of a transaction. The event will be
+	// available within the transaction in the committed block regardless of the
+	// validity of the transaction.
+	// Only a single event can be included in a transaction, and must originate
+	// from the outer-most invoked chaincode in chaincode-to-chaincode scenarios.
+	// The marshaled ChaincodeEvent will be available in the transaction's ChaincodeAction.events field.
+	SetEvent(name string, payload []byte) error
+}
+
+// StateReader reads the public world state.
+type StateReader interface {
 	// GetState returns the value of the specified `key` from the
 	// ledger. Note that GetState doesn't read data from the writeset, which
 	// has not been committed to the ledger. In other words, GetState doesn't
@@ -80,6 +147,14 @@ type ChaincodeStubInterface interface {
 	// If the key does not exist in the state database, (nil, nil) is returned.
 	GetState(key string) ([]byte, error)
 
+	// GetStateValidationParameter retrieves the key-level endorsement policy
+	// for `key`. Note that this will introduce a read dependency on `key` in
+	// the transaction's readset.
+	GetStateValidationParameter(key string) ([]byte, error)
+}
+
+// StateWriter modifies the public world state.
+type StateWriter interface {
 	// PutState puts the specified `key` and `value` into the transaction's
 	// writeset as a data-write proposal. PutState doesn't effect the ledger
 	// until the transaction is validated and successfully committed.
@@ -98,11 +173,26 @@ type ChaincodeStubInterface interface {
 	// SetStateValidationParameter sets the key-level endorsement policy for `key`.
 	SetStateValidationParameter(key string, ep []byte) error
 
-	// GetStateValidationParameter retrieves the key-level endorsement policy
-	// for `key`. Note that this will introduce a read dependency on `key` in
-	// the transaction's readset.
-	GetStateValidationParameter(key string) ([]byte, error)
+	// StartWriteBatch enables a mode where all changes are not immediately forwarded to the peer,
+	// but accumulate in the cache. The cache is sent in large batches either at the end of transaction
+	// execution or after the FinishWriteBatch call.
+	// IMPORTANT: in this mode, the expected order of transaction execution and expected errors can be changed.
+	//
+	// If write batching is not supported by the peer, this method has no effect
+	// and writes to the ledger continue to be processed immediately.
+	StartWriteBatch()
+
+	// FinishWriteBatch sends accumulated changes in large batches to the peer
+	// if StartWriteBatch has been called before it.
+	//
+	// If write batching is not supported by the peer or no write batch has been
+	// started, this method has no effect and returns nil.
+	FinishWriteBatch() error
+}
 
+// QueryExecutor runs range, composite key, rich and history queries against
+// the public world state.
+type QueryExecutor interface {
 	// GetStateByRange returns a range iterator over a set of keys in the
 	// ledger. The iterator can be used to iterate over all keys
 	// between the startKey (inclusive) and endKey (exclusive).
@@ -175,19 +265,6 @@ type ChaincodeStubInterface interface {
 	GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string,
 		pageSize int32, bookmark string) (StateQueryIteratorInterface, *peer.QueryResponseMetadata, error)
 
-	// CreateCompositeKey combines the given `attributes` to form a composite
-	// key. The objectType and attributes are expected to have only valid utf8
-	// strings and should not contain U+0000 (nil byte) and U+10FFFF
-	// (biggest and unallocated code point).
-	// The resulting composite key can be used as the key in PutState().
-	CreateCompositeKey(objectType string, attributes []string) (string, error)
-
-	// SplitCompositeKey splits the specified key into attributes on which the
-	// composite key was formed. Composite keys found during range queries
-	// or partial composite key queries can therefore be split into their
-	// composite parts.
-	SplitCompositeKey(compositeKey string) (string, []string, error)
-
 	// GetQueryResult performs a "rich" query against a state database. It is
 	// only supported for state databases that support rich query,
 	// e.g.CouchDB. The query string is in the native syntax
@@ -239,7 +316,10 @@ type ChaincodeStubInterface interface {
 	// This will allow applications to efficiently iterate through the top results
 	// to understand recent changes to a key.
 	GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error)
+}
 
+// PrivateDataAccessor reads and modifies private data collections.
+type PrivateDataAccessor interface {
 	// GetPrivateData returns the value of the specified `key` from the specified
 	// `collection`. Note that GetPrivateData doesn't read data from the
 	// private writeset, which has not been committed to the `collection`. In
@@ -328,64 +408,6 @@ type ChaincodeStubInterface interface {
 	// should therefore not use GetPrivateDataQueryResult as part of transactions that update
 	// ledger, and should limit use to read-only chaincode operations.
 	GetPrivateDataQueryResult(collection, query string) (StateQueryIteratorInterface, error)
-
-	// GetCreator returns `SignatureHeader.Creator` (e.g. an identity)
-	// of the `SignedProposal`. This is the identity of the agent (or user)
-	// submitting the transaction.
-	GetCreator() ([]byte, error)
-
-	// GetTransient returns the `ChaincodeProposalPayload.Transient` field.
-	// It is a map that contains data (e.g. cryptographic material)
-	// that might be used to implement some form of application-level
-	// confidentiality. The contents of this field, as prescribed by
-	// `ChaincodeProposalPayload`, are supposed to always
-	// be omitted from the transaction and excluded from the ledger.
-	GetTransient() (map[string][]byte, error)
-
-	// GetBinding returns the transaction binding, which is used to enforce a
-	// link between application data (like those stored in the transient field
-	// above) to the proposal itself. This is useful to avoid possible replay
-	// attacks.
-	GetBinding() ([]byte, error)
-
-	// GetDecorations returns additional data (if applicable) about the proposal
-	// that originated from the peer. This data is set by the decorators of the
-	// peer, which append or mutate the chaincode input passed to the chaincode.
-	GetDecorations() map[string][]byte
-
-	// GetSignedProposal returns the SignedProposal object, which contains all
-	// data elements part of a transaction proposal.
-	GetSignedProposal() (*peer.SignedProposal, error)
-
-	// GetTxTimestamp returns the timestamp when the transaction was created. This
-	// is taken from the transaction ChannelHeader, therefore it will indicate the
-	// client's timestamp and will have the same value across all endorsers.
-	GetTxTimestamp() (*timestamppb.Timestamp, error)
-
-	// SetEvent allows the chaincode to set an event on the response to the
-	// proposal to be included as part of a transaction. The event will be
-	// available within the transaction in the committed block regardless of the
-	// validity of the transaction.
-	// Only a single event can be included in a transaction, and must originate
-	// from the outer-most invoked chaincode in chaincode-to-chaincode scenarios.
-	// The marshaled ChaincodeEvent will be available in the transaction's ChaincodeAction.events field.
-	SetEvent(name string, payload []byte) error
-
-	// StartWriteBatch enables a mode where all changes are not immediately forwarded to the peer,
-	// but accumulate in the cache. The cache is sent in large batches either at the end of transaction
-	// execution or after the FinishWriteBatch call.
-	// IMPORTANT: in this mode, the expected order of transaction execution and expected errors can be changed.
-	//
-	// If write batching is not supported by the peer, this method has no effect
-	// and writes to the ledger continue to be processed immediately.
-	StartWriteBatch()
-
-	// FinishWriteBatch sends accumulated changes in large batches to the peer
-	// if StartWriteBatch has been called before it.
-	//
-	// If write batching is not supported by the peer or no write batch has been
-	// started, this method has no effect and returns nil.
-	FinishWriteBatch() error
 }
 
 // CommonIteratorInterface allows a chaincode to check whether any more result
@@ -396,10 +418,13 @@ type CommonIteratorInterface interface {
 	HasNext() bool
 
 	// Close closes the iterator. This should be called when done
-	// reading from the iterator to free up resources.
+	// reading from the iterator to free up resources. Iterators that are
+	// still open when Init or Invoke returns are closed by the shim.
 	Close() error
 }
 
+//go:generate counterfeiter -o mocks/state_query_iterator.go --fake-name StateQueryIterator . StateQueryIteratorInterface
+
 // StateQueryIteratorInterface allows a chaincode to iterate over a set of
 // key/value pairs returned by range and execute query.
 type StateQueryIteratorInterface interface {
@@ -410,6 +435,8 @@ type StateQueryIteratorInterface interface {
 	Next() (*queryresult.KV, error)
 }
 
+//go:generate counterfeiter -o mocks/history_query_iterator.go --fake-name HistoryQueryIterator . HistoryQueryIteratorInterface
+
 // HistoryQueryIteratorInterface allows a chaincode to iterate over a set of
 // key/value pairs returned by a history query.
 type HistoryQueryIteratorInterface interface {
@@ -9,11 +9,15 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"log"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
 	"time"
 
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
 )
 
 // Config contains chaincode's configuration
@@ -23,7 +27,20 @@ type Config struct {
 	KaOpts        keepalive.ClientParameters
 }
 
-// LoadConfig loads the chaincode configuration
+// ClientKeepaliveOptions are the keepalive options used to connect to the
+// peer. They are hardcoded to match the chaincode server.
+var ClientKeepaliveOptions = keepalive.ClientParameters{
+	Time:                1 * time.Minute,
+	Timeout:             20 * time.Second,
+	PermitWithoutStream: true,
+}
+
+// certificateExpiryWarning is how long before the client certificate expires
+// a warning is logged.
+const certificateExpiryWarning = 30 * 24 * time.Hour
+
+// LoadConfig loads the chaincode configuration. All problems found in the TLS
+// configuration are reported together.
 func LoadConfig() (Config, error) {
 	var err error
 	tlsEnabled, err := strconv.ParseBool(os.Getenv("CORE_PEER_TLS_ENABLED"))
@@ -33,69 +50,106 @@ func LoadConfig() (Config, error) {
 
 	conf := Config{
 		ChaincodeName: os.Getenv("CORE_CHAINCODE_ID_NAME"),
-		// hardcode to match chaincode server
-		KaOpts: keepalive.ClientParameters{
-			Time:                1 * time.Minute,
-			Timeout:             20 * time.Second,
-			PermitWithoutStream: true,
-		},
+		KaOpts:        ClientKeepaliveOptions,
 	}
 
 	if !tlsEnabled {
 		return conf, nil
 	}
 
-	var key []byte
-	path, set := os.LookupEnv("CORE_TLS_CLIENT_KEY_FILE")
-	if set {
-		key, err = os.ReadFile(path)
-		if err != nil {
-			return Config{}, fmt.Errorf("failed to read private key file: %s", err)
-		}
-	} else {
-		data, err := os.ReadFile(os.Getenv("CORE_TLS_CLIENT_KEY_PATH"))
-		if err != nil {
-			return Config{}, fmt.Errorf("failed to read private key file: %s", err)
-		}
-		key, err = base64.StdEncoding.DecodeString(string(data))
-		if err != nil {
-			return Config{}, fmt.Errorf("failed to decode private key file: %s", err)
-		}
+	key, keyErr := readTLSFile("CORE_TLS_CLIENT_KEY_FILE", "CORE_TLS_CLIENT_KEY_PATH", "private key")
+	cert, certErr := readTLSFile("CORE_TLS_CLIENT_CERT_FILE", "CORE_TLS_CLIENT_CERT_PATH", "public key")
+
+	var rootErr error
+	root, err := os.ReadFile(os.Getenv("CORE_PEER_TLS_ROOTCERT_FILE"))
+	if err != nil {
+		rootErr = fmt.Errorf("failed to read root cert file: %s", err)
+	} else if ok := x509.NewCertPool().AppendCertsFromPEM(root); !ok {
+		rootErr = errors.New("failed to load root cert file: no PEM encoded certificates found")
 	}
 
-	var cert []byte
-	path, set = os.LookupEnv("CORE_TLS_CLIENT_CERT_FILE")
-	if set {
-		cert, err = os.ReadFile(path)
-		if err != nil {
-			return Config{}, fmt.Errorf("failed to read public key file: %s", err)
-		}
-	} else {
-		data, err := os.ReadFile(os.Getenv("CORE_TLS_CLIENT_CERT_PATH"))
-		if err != nil {
-			return Config{}, fmt.Errorf("failed to read public key file: %s", err)
-		}
-		cert, err = base64.StdEncoding.DecodeString(string(data))
-		if err != nil {
-			return Config{}, fmt.Errorf("failed to decode public key file: %s", err)
+	var pairErr error
+	if keyErr == nil && certErr == nil {
+		if _, err := tls.X509KeyPair(cert, key); err != nil {
+			pairErr = fmt.Errorf("failed to parse client key pair: %s", err)
 		}
 	}
 
-	root, err := os.ReadFile(os.Getenv("CORE_PEER_TLS_ROOTCERT_FILE"))
-	if err != nil {
-		return Config{}, fmt.Errorf("failed to read root cert file: %s", err)
+	if err := errors.Join(keyErr, certErr, rootErr, pairErr); err != nil {
+		return Config{}, err
 	}
 
 	tlscfg, err := LoadTLSConfig(false, key, cert, root)
 	if err != nil {
 		return Config{}, err
 	}
+	leaf, err := x509.ParseCertificate(tlscfg.Certificates[0].Certificate[0])
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to parse client certificate: %s", err)
+	}
+	warnCertificateExpiry(leaf, time.Now())
 
 	conf.TLS = tlscfg
 
 	return conf, nil
 }
 
+// readTLSFile reads a PEM encoded file named by fileEnv or, if that is not
+// set, a base64 encoded PEM file named by pathEnv.
+func readTLSFile(fileEnv, pathEnv, what string) ([]byte, error) {
+	path, set := os.LookupEnv(fileEnv)
+	if set {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s file: %s", what, err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(os.Getenv(pathEnv))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s file: %s", what, err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s file %s: %s", what, os.Getenv(pathEnv), err)
+	}
+	return decoded, nil
+}
+
+// warnCertificateExpiry logs a warning if cert is not valid at now or expires
+// soon. The peer is left to reject a certificate that is not valid.
+func warnCertificateExpiry(cert *x509.Certificate, now time.Time) {
+	switch {
+	case now.Before(cert.NotBefore):
+		log.Printf("Warning: client certificate is not valid before %s", cert.NotBefore.Format(time.RFC3339))
+	case now.After(cert.NotAfter):
+		log.Printf("Warning: client certificate expired on %s", cert.NotAfter.Format(time.RFC3339))
+	case cert.NotAfter.Sub(now) < certificateExpiryWarning:
+		log.Printf("Warning: client certificate expires on %s", cert.NotAfter.Format(time.RFC3339))
+	}
+}
+
+// ValidateAddress checks that address is of the form host:port. Addresses
+// with a scheme gRPC resolves, such as dns:///host:port or unix:///path, are
+// left for gRPC to check.
+func ValidateAddress(address string) error {
+	if u, err := url.Parse(address); err == nil && resolver.Get(u.Scheme) != nil {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid peer address %s: %s", address, err)
+	}
+	if host == "" {
+		return fmt.Errorf("invalid peer address %s: missing host", address)
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return fmt.Errorf("invalid peer address %s: invalid port %s", address, port)
+	}
+	return nil
+}
+
 // LoadTLSConfig loads the TLS configuration for the chaincode
 func LoadTLSConfig(isserver bool, key, cert, root []byte) (*tls.Config, error) {
 	if key == nil {
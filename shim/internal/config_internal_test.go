@@ -0,0 +1,50 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"crypto/x509"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarnCertificateExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cert := &x509.Certificate{
+		NotBefore: now.Add(-24 * time.Hour),
+		NotAfter:  now.Add(365 * 24 * time.Hour),
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	var tests = []struct {
+		name     string
+		now      time.Time
+		expected string
+	}{
+		{name: "Valid", now: now},
+		{name: "Expiring Soon", now: cert.NotAfter.Add(-time.Hour), expected: "Warning: client certificate expires on 2024-12-31T00:00:00Z"},
+		{name: "Expired", now: cert.NotAfter.Add(time.Hour), expected: "Warning: client certificate expired on 2024-12-31T00:00:00Z"},
+		{name: "Not Yet Valid", now: cert.NotBefore.Add(-time.Hour), expected: "Warning: client certificate is not valid before 2023-12-31T00:00:00Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf.Reset()
+			warnCertificateExpiry(cert, test.now)
+			if test.expected == "" {
+				assert.Empty(t, buf.String())
+			} else {
+				assert.Contains(t, buf.String(), test.expected)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -581,6 +582,65 @@ func TestLoadPEMEncodedConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfigReportsAllErrors(t *testing.T) {
+	notb64File, err := os.CreateTemp(t.TempDir(), "testNotb64")
+	assert.NoError(t, err)
+	_, err = notb64File.WriteString("#####")
+	assert.NoError(t, err)
+	notb64File.Close()
+
+	for k, v := range map[string]string{
+		"CORE_PEER_TLS_ENABLED":       "true",
+		"CORE_TLS_CLIENT_KEY_PATH":    "missingkey",
+		"CORE_TLS_CLIENT_CERT_PATH":   notb64File.Name(),
+		"CORE_PEER_TLS_ROOTCERT_FILE": notb64File.Name(),
+	} {
+		t.Setenv(k, v)
+	}
+	for _, k := range []string{"CORE_TLS_CLIENT_KEY_FILE", "CORE_TLS_CLIENT_CERT_FILE"} {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "failed to read private key file")
+	assert.ErrorContains(t, err, "failed to decode public key file")
+	assert.ErrorContains(t, err, "failed to load root cert file")
+}
+
+func TestLoadConfigReportsKeyPairMismatch(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"key": clientKeyPEM, "cert": certPEM, "root": "not a certificate"}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	for k, v := range map[string]string{
+		"CORE_PEER_TLS_ENABLED":       "true",
+		"CORE_TLS_CLIENT_KEY_FILE":    filepath.Join(dir, "key"),
+		"CORE_TLS_CLIENT_CERT_FILE":   filepath.Join(dir, "cert"),
+		"CORE_PEER_TLS_ROOTCERT_FILE": filepath.Join(dir, "root"),
+	} {
+		t.Setenv(k, v)
+	}
+
+	_, err := LoadConfig()
+	assert.ErrorContains(t, err, "failed to load root cert file")
+	assert.ErrorContains(t, err, "failed to parse client key pair")
+}
+
+func TestValidateAddress(t *testing.T) {
+	assert.NoError(t, ValidateAddress("peer0.org1.example.com:7052"))
+	assert.NoError(t, ValidateAddress("[::1]:7052"))
+	assert.NoError(t, ValidateAddress("dns:///peer0.org1.example.com:7052"))
+	assert.NoError(t, ValidateAddress("unix:///var/run/peer.sock"))
+	assert.NoError(t, ValidateAddress("passthrough:///peer0:7052"))
+	assert.EqualError(t, ValidateAddress("localhost"), "invalid peer address localhost: address localhost: missing port in address")
+	assert.EqualError(t, ValidateAddress("127.0.0.1"), "invalid peer address 127.0.0.1: address 127.0.0.1: missing port in address")
+	assert.EqualError(t, ValidateAddress(":7052"), "invalid peer address :7052: missing host")
+	assert.EqualError(t, ValidateAddress("peer:http"), "invalid peer address peer:http: invalid port http")
+	assert.EqualError(t, ValidateAddress("peer:70520"), "invalid peer address peer:70520: invalid port 70520")
+}
+
 func newTLSConnection(t *testing.T, address string, crt, key, rootCert []byte) *grpc.ClientConn {
 	tlsConfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
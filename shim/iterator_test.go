@@ -0,0 +1,102 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package shim_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// leakingChaincode opens query iterators without closing them.
+type leakingChaincode struct {
+	iterators int
+}
+
+func (cc *leakingChaincode) Init(stub shim.ChaincodeStubInterface) *peer.Response {
+	return shim.Success(nil)
+}
+
+func (cc *leakingChaincode) Invoke(stub shim.ChaincodeStubInterface) *peer.Response {
+	for i := 0; i < cc.iterators; i++ {
+		if _, err := stub.GetStateByRange("start", "end"); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	return shim.Success(nil)
+}
+
+// iteratorPeer is a ClientStream that answers the chaincode like a peer
+// running a single transaction, and records the iterators it is asked to
+// close.
+type iteratorPeer struct {
+	toChaincode chan *peer.ChaincodeMessage
+	opened      int
+	closed      []string
+}
+
+func (ip *iteratorPeer) Send(msg *peer.ChaincodeMessage) error {
+	respond := func(payload proto.Message) {
+		bytes, err := proto.Marshal(payload)
+		if err != nil {
+			panic(err)
+		}
+		ip.toChaincode <- &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_RESPONSE, ChannelId: msg.GetChannelId(), Txid: msg.GetTxid(), Payload: bytes}
+	}
+
+	switch msg.GetType() {
+	case peer.ChaincodeMessage_REGISTER:
+		ip.toChaincode <- &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_REGISTERED}
+		ip.toChaincode <- &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_READY}
+		ip.toChaincode <- &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_TRANSACTION, ChannelId: "channel", Txid: "txid"}
+	case peer.ChaincodeMessage_GET_STATE_BY_RANGE:
+		ip.opened++
+		respond(&peer.QueryResponse{Id: fmt.Sprintf("iterator%d", ip.opened)})
+	case peer.ChaincodeMessage_QUERY_STATE_CLOSE:
+		req := &peer.QueryStateClose{}
+		if err := proto.Unmarshal(msg.GetPayload(), req); err != nil {
+			return err
+		}
+		ip.closed = append(ip.closed, req.GetId())
+		respond(&peer.QueryResponse{Id: req.GetId()})
+	case peer.ChaincodeMessage_COMPLETED, peer.ChaincodeMessage_ERROR:
+		close(ip.toChaincode)
+	}
+	return nil
+}
+
+func (ip *iteratorPeer) Recv() (*peer.ChaincodeMessage, error) {
+	msg, ok := <-ip.toChaincode
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func (ip *iteratorPeer) CloseSend() error {
+	return nil
+}
+
+func TestLeakedIteratorsAreClosedInOrder(t *testing.T) {
+	t.Setenv("CORE_CHAINCODE_ITERATOR_LEAK_DETECTION", "true")
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	stream := &iteratorPeer{toChaincode: make(chan *peer.ChaincodeMessage, 10)}
+	err := shim.StartInProc("cc", stream, &leakingChaincode{iterators: 5})
+	assert.EqualError(t, err, "received EOF, ending chaincode stream")
+
+	assert.Equal(t, []string{"iterator1", "iterator2", "iterator3", "iterator4", "iterator5"}, stream.closed)
+	assert.Contains(t, logs.String(), "query iterator created at github.com/hyperledger/fabric-chaincode-go/v2/shim_test.(*leakingChaincode).Invoke (")
+	assert.Contains(t, logs.String(), "iterator_test.go:31) was not closed by the chaincode")
+}
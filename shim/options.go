@@ -0,0 +1,52 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package shim
+
+import "crypto/tls"
+
+// StartOption configures how Start connects the chaincode to the peer. Options
+// take precedence over the environment variables and flags set by the peer.
+type StartOption func(*startOptions)
+
+type startOptions struct {
+	chaincodeID  string
+	peerAddress  string
+	tlsConfig    *tls.Config
+	tlsConfigSet bool
+	interceptors []MessageInterceptor
+}
+
+// WithChaincodeID sets the ID the chaincode registers with, instead of
+// CORE_CHAINCODE_ID_NAME.
+func WithChaincodeID(id string) StartOption {
+	return func(o *startOptions) {
+		o.chaincodeID = id
+	}
+}
+
+// WithPeerAddress sets the address of the peer to connect to, instead of the
+// peer.address flag.
+func WithPeerAddress(address string) StartOption {
+	return func(o *startOptions) {
+		o.peerAddress = address
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the peer,
+// instead of the CORE_PEER_TLS_* and CORE_TLS_CLIENT_* environment variables.
+// A nil config disables TLS.
+func WithTLSConfig(config *tls.Config) StartOption {
+	return func(o *startOptions) {
+		o.tlsConfig = config
+		o.tlsConfigSet = true
+	}
+}
+
+// WithMessageInterceptors adds interceptors that observe the messages
+// exchanged with the peer.
+func WithMessageInterceptors(interceptors ...MessageInterceptor) StartOption {
+	return func(o *startOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package shim
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxRecordedMessageSize is the maximum length of a line in a recording.
+const maxRecordedMessageSize = 100 * 1024 * 1024
+
+// recordedMessage is a message in a recording, written as one line of JSON.
+type recordedMessage struct {
+	// Sent is true for messages sent by the chaincode to the peer.
+	Sent    bool            `json:"sent"`
+	Message json.RawMessage `json:"message"`
+}
+
+// MessageRecorder is a MessageInterceptor that records the messages exchanged
+// with the peer, one JSON object per line, so that they can be replayed in
+// tests with a ReplayStream.
+type MessageRecorder struct {
+	mutex sync.Mutex
+	w     io.Writer
+	err   error
+}
+
+// NewMessageRecorder returns a MessageRecorder that writes to w. Sent messages
+// are written while the handler holds its send lock, so w should be fast, for
+// example a bytes.Buffer or a bufio.Writer flushed once the chaincode exits.
+func NewMessageRecorder(w io.Writer) *MessageRecorder {
+	return &MessageRecorder{w: w}
+}
+
+// MessageReceived records a message received from the peer.
+func (r *MessageRecorder) MessageReceived(msg *peer.ChaincodeMessage) {
+	r.record(false, msg)
+}
+
+// MessageSent records a message sent to the peer.
+func (r *MessageRecorder) MessageSent(msg *peer.ChaincodeMessage) {
+	r.record(true, msg)
+}
+
+// Err returns the first error encountered while recording.
+func (r *MessageRecorder) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+func (r *MessageRecorder) record(sent bool, msg *peer.ChaincodeMessage) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return
+	}
+
+	message, err := protojson.Marshal(msg)
+	if err != nil {
+		r.err = fmt.Errorf("failed to marshal %s message: %s", msg.GetType(), err)
+		return
+	}
+	line, err := json.Marshal(&recordedMessage{Sent: sent, Message: message})
+	if err != nil {
+		r.err = fmt.Errorf("failed to marshal %s message: %s", msg.GetType(), err)
+		return
+	}
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		r.err = fmt.Errorf("failed to write %s message: %s", msg.GetType(), err)
+	}
+}
+
+// ReplayStream is a ClientStream that plays the part of the peer in a
+// recording made by a MessageRecorder. It can be passed to StartInProc to run
+// a chaincode against the recorded peer behaviour without a network.
+//
+// Recv returns the recorded messages from the peer in order, waiting until
+// the chaincode has sent the messages recorded before them, and io.EOF at the
+// end of the recording. Send fails if the chaincode sends a message that does
+// not match the recording.
+type ReplayStream struct {
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	messages []*replayedMessage
+	next     int
+	err      error
+}
+
+type replayedMessage struct {
+	sent bool
+	msg  *peer.ChaincodeMessage
+}
+
+// NewReplayStream returns a ReplayStream for the recording read from r.
+func NewReplayStream(r io.Reader) (*ReplayStream, error) {
+	rs := &ReplayStream{}
+	rs.cond = sync.NewCond(&rs.mutex)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordedMessageSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		recorded := &recordedMessage{}
+		if err := json.Unmarshal(scanner.Bytes(), recorded); err != nil {
+			return nil, fmt.Errorf("invalid recording at line %d: %s", line, err)
+		}
+		msg := &peer.ChaincodeMessage{}
+		if err := protojson.Unmarshal(recorded.Message, msg); err != nil {
+			return nil, fmt.Errorf("invalid recording at line %d: %s", line, err)
+		}
+		rs.messages = append(rs.messages, &replayedMessage{sent: recorded.Sent, msg: msg})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %s", err)
+	}
+
+	return rs, nil
+}
+
+// Send checks that msg is the next message the chaincode sent in the
+// recording.
+func (rs *ReplayStream) Send(msg *peer.ChaincodeMessage) error {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	defer rs.cond.Broadcast()
+
+	if rs.err != nil {
+		return rs.err
+	}
+	if rs.next >= len(rs.messages) || !rs.messages[rs.next].sent {
+		rs.err = fmt.Errorf("unexpected %s message sent by the chaincode", msg.GetType())
+		return rs.err
+	}
+	if expected := rs.messages[rs.next].msg; !proto.Equal(expected, msg) {
+		rs.err = fmt.Errorf("%s message sent by the chaincode does not match the recorded %s message", msg.GetType(), expected.GetType())
+		return rs.err
+	}
+	rs.next++
+	return nil
+}
+
+// Recv returns the next message the peer sent in the recording.
+func (rs *ReplayStream) Recv() (*peer.ChaincodeMessage, error) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	for rs.err == nil && rs.next < len(rs.messages) && rs.messages[rs.next].sent {
+		rs.cond.Wait()
+	}
+	if rs.err != nil {
+		return nil, rs.err
+	}
+	if rs.next == len(rs.messages) {
+		return nil, io.EOF
+	}
+
+	msg := rs.messages[rs.next].msg
+	rs.next++
+	rs.cond.Broadcast()
+	return msg, nil
+}
+
+// CloseSend does nothing.
+func (rs *ReplayStream) CloseSend() error {
+	return nil
+}
+
+// Err returns the error encountered if the chaincode diverged from the
+// recording, or an error if the recording was not fully replayed.
+func (rs *ReplayStream) Err() error {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if rs.err != nil {
+		return rs.err
+	}
+	if rs.next < len(rs.messages) {
+		return fmt.Errorf("%d of %d recorded messages were not replayed", len(rs.messages)-rs.next, len(rs.messages))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright the Hyperledger Fabric contributors. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package shim
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type getStateChaincode struct {
+	key   string
+	value []byte
+}
+
+func (cc *getStateChaincode) Init(stub ChaincodeStubInterface) *peer.Response {
+	return Success(nil)
+}
+
+func (cc *getStateChaincode) Invoke(stub ChaincodeStubInterface) *peer.Response {
+	value, err := stub.GetState(cc.key)
+	if err != nil {
+		return Error(err.Error())
+	}
+	cc.value = value
+	return Success(value)
+}
+
+// scriptedPeer is a ClientStream that answers the chaincode like a peer
+// running a single transaction.
+type scriptedPeer struct {
+	toChaincode chan *peer.ChaincodeMessage
+}
+
+func (sp *scriptedPeer) Send(msg *peer.ChaincodeMessage) error {
+	switch msg.GetType() {
+	case peer.ChaincodeMessage_REGISTER:
+		sp.toChaincode <- &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_REGISTERED}
+		sp.toChaincode <- &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_READY}
+		sp.toChaincode <- &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_TRANSACTION, ChannelId: "channel", Txid: "txid"}
+	case peer.ChaincodeMessage_GET_STATE:
+		sp.toChaincode <- &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_RESPONSE, ChannelId: "channel", Txid: "txid", Payload: []byte("recorded value")}
+	case peer.ChaincodeMessage_COMPLETED:
+		close(sp.toChaincode)
+	}
+	return nil
+}
+
+func (sp *scriptedPeer) Recv() (*peer.ChaincodeMessage, error) {
+	msg, ok := <-sp.toChaincode
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func (sp *scriptedPeer) CloseSend() error {
+	return nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	recording := &bytes.Buffer{}
+	recorder := NewMessageRecorder(recording)
+	stream := &scriptedPeer{toChaincode: make(chan *peer.ChaincodeMessage, 10)}
+	err := chaincodeAsClientChat("cc", stream, &getStateChaincode{key: "key"}, recorder)
+	assert.EqualError(t, err, "received EOF, ending chaincode stream")
+	require.NoError(t, recorder.Err())
+	assert.Equal(t, 7, strings.Count(recording.String(), "\n"))
+
+	replay, err := NewReplayStream(bytes.NewReader(recording.Bytes()))
+	require.NoError(t, err)
+	cc := &getStateChaincode{key: "key"}
+	err = StartInProc("cc", replay, cc)
+	assert.EqualError(t, err, "received EOF, ending chaincode stream")
+	assert.NoError(t, replay.Err())
+	assert.Equal(t, []byte("recorded value"), cc.value)
+
+	replay, err = NewReplayStream(bytes.NewReader(recording.Bytes()))
+	require.NoError(t, err)
+	err = StartInProc("cc", replay, &getStateChaincode{key: "otherkey"})
+	assert.ErrorContains(t, err, "GET_STATE message sent by the chaincode does not match the recorded GET_STATE message")
+	assert.EqualError(t, replay.Err(), "GET_STATE message sent by the chaincode does not match the recorded GET_STATE message")
+}
+
+func TestNewReplayStreamInvalidRecording(t *testing.T) {
+	_, err := NewReplayStream(strings.NewReader("{\"sent\":true,\"message\":{}}\nnot json\n"))
+	assert.ErrorContains(t, err, "invalid recording at line 2")
+
+	_, err = NewReplayStream(strings.NewReader("{\"sent\":true,\"message\":{\"type\":\"NOT_A_TYPE\"}}\n"))
+	assert.ErrorContains(t, err, "invalid recording at line 1")
+}
+
+func TestReplayStreamNotFullyReplayed(t *testing.T) {
+	recording := `{"sent":false,"message":{"type":"REGISTERED"}}` + "\n" + `{"sent":true,"message":{"type":"COMPLETED"}}` + "\n"
+	replay, err := NewReplayStream(strings.NewReader(recording))
+	require.NoError(t, err)
+
+	msg, err := replay.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, peer.ChaincodeMessage_REGISTERED, msg.GetType())
+	assert.EqualError(t, replay.Err(), "1 of 2 recorded messages were not replayed")
+
+	assert.EqualError(t, replay.Send(&peer.ChaincodeMessage{Type: peer.ChaincodeMessage_REGISTER}), "REGISTER message sent by the chaincode does not match the recorded COMPLETED message")
+	_, err = replay.Recv()
+	assert.EqualError(t, err, "REGISTER message sent by the chaincode does not match the recorded COMPLETED message")
+}
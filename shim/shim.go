@@ -23,6 +23,10 @@ const (
 	maxUnicodeRuneValue   = utf8.MaxRune // U+10FFFF - maximum (and unallocated) code point
 	compositeKeyNamespace = "\x00"
 	emptyKeySubstitute    = "\x01"
+
+	// shimPackagePrefix prefixes the names of all functions in this package,
+	// as reported by the runtime.
+	shimPackagePrefix = "github.com/hyperledger/fabric-chaincode-go/v2/shim."
 )
 
 // peer as server
@@ -36,17 +40,34 @@ type peerStreamGetter func(name string) (ClientStream, error)
 var streamGetter peerStreamGetter
 
 // the non-mock user CC stream establishment func
-func userChaincodeStreamGetter(name string) (ClientStream, error) {
-	if *peerAddress == "" {
-		return nil, errors.New("flag 'peer.address' must be set")
+func (o *startOptions) userChaincodeStreamGetter(name string) (ClientStream, error) {
+	address := o.peerAddress
+	if address == "" {
+		address = *peerAddress
 	}
 
-	conf, err := internal.LoadConfig()
-	if err != nil {
+	// validate the address and the configuration together so that all
+	// problems are reported at once
+	var addressErr error
+	if address == "" {
+		addressErr = errors.New("flag 'peer.address' must be set")
+	} else {
+		addressErr = internal.ValidateAddress(address)
+	}
+	conf := internal.Config{
+		ChaincodeName: name,
+		TLS:           o.tlsConfig,
+		KaOpts:        internal.ClientKeepaliveOptions,
+	}
+	var confErr error
+	if !o.tlsConfigSet {
+		conf, confErr = internal.LoadConfig()
+	}
+	if err := errors.Join(addressErr, confErr); err != nil {
 		return nil, err
 	}
 
-	conn, err := internal.NewClientConn(*peerAddress, conf.TLS, conf.KaOpts)
+	conn, err := internal.NewClientConn(address, conf.TLS, conf.KaOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -54,25 +75,36 @@ func userChaincodeStreamGetter(name string) (ClientStream, error) {
 	return internal.NewRegisterClient(conn)
 }
 
-// Start chaincodes
-func Start(cc Chaincode) error {
+// Start chaincodes. Without options the chaincode is configured by the
+// environment and the peer.address flag, which are set by the peer when it
+// launches the chaincode.
+func Start(cc Chaincode, opts ...StartOption) error {
 	flag.Parse()
-	chaincodename := os.Getenv("CORE_CHAINCODE_ID_NAME")
+	o := &startOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	chaincodename := o.chaincodeID
+	if chaincodename == "" {
+		chaincodename = os.Getenv("CORE_CHAINCODE_ID_NAME")
+	}
 	if chaincodename == "" {
 		return errors.New("'CORE_CHAINCODE_ID_NAME' must be set")
 	}
 
 	// mock stream not set up ... get real stream
-	if streamGetter == nil {
-		streamGetter = userChaincodeStreamGetter
+	getStream := streamGetter
+	if getStream == nil {
+		getStream = o.userChaincodeStreamGetter
 	}
 
-	stream, err := streamGetter(chaincodename)
+	stream, err := getStream(chaincodename)
 	if err != nil {
 		return err
 	}
 
-	err = chaincodeAsClientChat(chaincodename, stream, cc)
+	err = chaincodeAsClientChat(chaincodename, stream, cc, o.interceptors...)
 
 	return err
 }
@@ -84,15 +116,15 @@ func StartInProc(chaincodename string, stream ClientStream, cc Chaincode) error
 }
 
 // this is the chat stream resulting from the chaincode-as-client model where the chaincode initiates connection
-func chaincodeAsClientChat(chaincodename string, stream ClientStream, cc Chaincode) error {
+func chaincodeAsClientChat(chaincodename string, stream ClientStream, cc Chaincode, interceptors ...MessageInterceptor) error {
 	defer stream.CloseSend() //nolint:errcheck
-	return chatWithPeer(chaincodename, stream, cc)
+	return chatWithPeer(chaincodename, stream, cc, interceptors...)
 }
 
 // chat stream for peer-chaincode interactions post connection
-func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode) error {
+func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode, interceptors ...MessageInterceptor) error {
 	// Create the shim handler responsible for all control logic
-	handler := newChaincodeHandler(stream, cc)
+	handler := newChaincodeHandler(stream, cc, interceptors...)
 
 	// Send the ChaincodeID during register.
 	chaincodeID := &peer.ChaincodeID{Name: chaincodename}
@@ -31,6 +31,7 @@ func TestStart(t *testing.T) {
 		chaincodeAddress string
 		streamGetter     func(name string) (ClientStream, error)
 		cc               Chaincode
+		opts             []StartOption
 		expectedErr      string
 	}{
 		{
@@ -41,9 +42,17 @@ func TestStart(t *testing.T) {
 			name: "Missing Peer Address",
 			envVars: map[string]string{
 				"CORE_CHAINCODE_ID_NAME": "cc",
+				"CORE_PEER_TLS_ENABLED":  "false",
 			},
 			expectedErr: "flag 'peer.address' must be set",
 		},
+		{
+			name: "Missing Peer Address and TLS Not Set",
+			envVars: map[string]string{
+				"CORE_CHAINCODE_ID_NAME": "cc",
+			},
+			expectedErr: "flag 'peer.address' must be set\n'CORE_PEER_TLS_ENABLED' must be set to 'true' or 'false'",
+		},
 		{
 			name: "TLS Not Set",
 			envVars: map[string]string{
@@ -61,6 +70,23 @@ func TestStart(t *testing.T) {
 			peerAddress: "127.0.0.1:12345",
 			expectedErr: `rpc error: code = Unavailable desc = connection error: desc = "transport: Error while dialing: dial tcp 127.0.0.1:12345: connect: connection refused"`,
 		},
+		{
+			name: "Connection Error with options",
+			opts: []StartOption{
+				WithChaincodeID("cc"),
+				WithPeerAddress("127.0.0.1:12345"),
+				WithTLSConfig(nil),
+			},
+			expectedErr: `rpc error: code = Unavailable desc = connection error: desc = "transport: Error while dialing: dial tcp 127.0.0.1:12345: connect: connection refused"`,
+		},
+		{
+			name: "Chaincode ID option",
+			opts: []StartOption{WithChaincodeID("ccopt")},
+			streamGetter: func(name string) (ClientStream, error) {
+				return nil, errors.New("stream for " + name)
+			},
+			expectedErr: "stream for ccopt",
+		},
 		{
 			name: "Chat - Nil Message",
 			envVars: map[string]string{
@@ -134,7 +160,7 @@ func TestStart(t *testing.T) {
 			}
 			peerAddress = &test.peerAddress
 			streamGetter = test.streamGetter
-			err := Start(test.cc)
+			err := Start(test.cc, test.opts...)
 			assert.EqualError(t, err, test.expectedErr)
 		})
 	}
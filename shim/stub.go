@@ -8,7 +8,12 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"log"
 	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"unicode/utf8"
 
 	"github.com/hyperledger/fabric-protos-go-apiv2/common"
@@ -37,6 +42,18 @@ type ChaincodeStub struct {
 	binding   []byte
 
 	decorations map[string][]byte
+
+	// iterators holds the query iterators opened during the transaction that
+	// have not been closed yet, numbered in the order they were created.
+	iteratorsLock sync.Mutex
+	iterators     map[*CommonIterator]openIterator
+	iteratorCount int
+}
+
+// openIterator records when and where a query iterator was created.
+type openIterator struct {
+	seq      int
+	callSite string
 }
 
 // ChaincodeInvocation functionality
@@ -215,13 +232,85 @@ func (s *ChaincodeStub) putState(collection string, key string, value []byte) er
 
 func (s *ChaincodeStub) createStateQueryIterator(response *peer.QueryResponse) *StateQueryIterator {
 	return &StateQueryIterator{
-		CommonIterator: &CommonIterator{
-			handler:    s.handler,
-			channelID:  s.ChannelID,
-			txid:       s.TxID,
-			response:   response,
-			currentLoc: 0,
-		},
+		CommonIterator: s.createCommonIterator(response),
+	}
+}
+
+func (s *ChaincodeStub) createCommonIterator(response *peer.QueryResponse) *CommonIterator {
+	iter := &CommonIterator{
+		handler:    s.handler,
+		channelID:  s.ChannelID,
+		txid:       s.TxID,
+		response:   response,
+		currentLoc: 0,
+		stub:       s,
+	}
+
+	var callSite string
+	if s.handler.iteratorLeakDetection {
+		callSite = iteratorCallSite()
+	}
+
+	s.iteratorsLock.Lock()
+	defer s.iteratorsLock.Unlock()
+	if s.iterators == nil {
+		s.iterators = map[*CommonIterator]openIterator{}
+	}
+	s.iteratorCount++
+	s.iterators[iter] = openIterator{seq: s.iteratorCount, callSite: callSite}
+
+	return iter
+}
+
+func (s *ChaincodeStub) untrackIterator(iter *CommonIterator) {
+	s.iteratorsLock.Lock()
+	defer s.iteratorsLock.Unlock()
+	delete(s.iterators, iter)
+}
+
+// closeIterators closes the query iterators the chaincode left open. It is
+// called once the chaincode has returned from Init or Invoke. The iterators
+// are closed in the order they were created, so that the messages sent to the
+// peer are the same on every run. When leak detection is enabled, the call
+// site that created each iterator is logged.
+func (s *ChaincodeStub) closeIterators() {
+	s.iteratorsLock.Lock()
+	open := s.iterators
+	s.iterators = nil
+	s.iteratorsLock.Unlock()
+
+	leaked := make([]*CommonIterator, 0, len(open))
+	for iter := range open {
+		leaked = append(leaked, iter)
+	}
+	sort.Slice(leaked, func(i, j int) bool {
+		return open[leaked[i]].seq < open[leaked[j]].seq
+	})
+
+	for _, iter := range leaked {
+		if s.handler.iteratorLeakDetection {
+			log.Printf("[%s] query iterator created at %s was not closed by the chaincode", shorttxid(s.TxID), open[iter].callSite)
+		}
+		if _, err := iter.handler.handleQueryStateClose(iter.response.Id, iter.channelID, iter.txid); err != nil {
+			log.Printf("[%s] failed to close leaked query iterator: %s", shorttxid(s.TxID), err)
+		}
+	}
+}
+
+// iteratorCallSite returns the location of the first caller outside of the
+// shim package.
+func iteratorCallSite() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, shimPackagePrefix) {
+			return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
 	}
 }
 
@@ -385,6 +474,7 @@ type CommonIterator struct {
 	txid       string
 	response   *peer.QueryResponse
 	currentLoc int
+	stub       *ChaincodeStub
 }
 
 // StateQueryIterator documentation can be found in interfaces.go
@@ -478,7 +568,7 @@ func (s *ChaincodeStub) GetHistoryForKey(key string) (HistoryQueryIteratorInterf
 	if err != nil {
 		return nil, err
 	}
-	return &HistoryQueryIterator{CommonIterator: &CommonIterator{s.handler, s.ChannelID, s.TxID, response, 0}}, nil
+	return &HistoryQueryIterator{CommonIterator: s.createCommonIterator(response)}, nil
 }
 
 // CreateCompositeKey documentation can be found in interfaces.go
@@ -631,6 +721,7 @@ func (s *ChaincodeStub) StartWriteBatch() {
 // FinishWriteBatch documentation can be found in interfaces.go
 func (s *ChaincodeStub) FinishWriteBatch() error {
 	err := s.handler.sendBatch(s.ChannelID, s.TxID, s.writeBatch.Writes())
+	s.writeBatch.release()
 	s.writeBatch = nil
 	return err
 }
@@ -727,6 +818,9 @@ func (iter *CommonIterator) nextResult(rType resultType) (queryResult, error) {
 
 // Close documentation can be found in interfaces.go
 func (iter *CommonIterator) Close() error {
+	if iter.stub != nil {
+		iter.stub.untrackIterator(iter)
+	}
 	_, err := iter.handler.handleQueryStateClose(iter.response.Id, iter.channelID, iter.txid)
 	return err
 }
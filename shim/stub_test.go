@@ -659,3 +659,57 @@ func TestChaincodeStubHandlers(t *testing.T) {
 		})
 	}
 }
+
+func TestChaincodeStubCloseIterators(t *testing.T) {
+	handler := &Handler{
+		cc:               &mockChaincode{},
+		responseChannels: map[string]chan *peer.ChaincodeMessage{},
+		state:            ready,
+	}
+	stub := &ChaincodeStub{
+		ChannelID: "channel",
+		TxID:      "txid",
+		handler:   handler,
+	}
+
+	var closed []string
+	chatStream := &mock.PeerChaincodeStream{}
+	chatStream.SendStub = func(msg *peer.ChaincodeMessage) error {
+		if msg.GetType() == peer.ChaincodeMessage_QUERY_STATE_CLOSE {
+			req := &peer.QueryStateClose{}
+			require.NoError(t, proto.Unmarshal(msg.GetPayload(), req))
+			closed = append(closed, req.GetId())
+		}
+		go func() {
+			err := handler.handleResponse(
+				&peer.ChaincodeMessage{
+					Type:      peer.ChaincodeMessage_RESPONSE,
+					ChannelId: msg.GetChannelId(),
+					Txid:      msg.GetTxid(),
+					Payload:   marshalOrPanic(&peer.QueryResponse{Id: "id-" + msg.GetType().String()}),
+				},
+			)
+			assert.NoError(t, err, "handleResponse")
+		}()
+		return nil
+	}
+	handler.chatStream = chatStream
+
+	sqi, err := stub.GetStateByRange("start", "end")
+	require.NoError(t, err)
+	_, err = stub.GetHistoryForKey("key")
+	require.NoError(t, err)
+	require.Len(t, stub.iterators, 2)
+
+	require.NoError(t, sqi.Close())
+	assert.Equal(t, []string{"id-GET_STATE_BY_RANGE"}, closed)
+	assert.Len(t, stub.iterators, 1)
+
+	stub.closeIterators()
+	assert.Equal(t, []string{"id-GET_STATE_BY_RANGE", "id-GET_HISTORY_FOR_KEY"}, closed)
+	assert.Empty(t, stub.iterators)
+
+	// nothing left to close
+	stub.closeIterators()
+	assert.Len(t, closed, 2)
+}